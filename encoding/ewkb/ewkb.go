@@ -0,0 +1,549 @@
+// Package ewkb implements Extended Well Known Binary encoding and decoding.
+package ewkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/ISI-nc/go-geom"
+	"github.com/ISI-nc/go-geom/encoding/wkbcommon"
+)
+
+// EWKB dimension and SRID flag bits, ORed into the high byte of the WKB type
+// code. These are wkbcommon's, not ewkb's own, so that wkbcommon.Validate
+// can parse an EWKB header without depending on this package.
+const (
+	zFlag    = wkbcommon.ZFlag
+	mFlag    = wkbcommon.MFlag
+	sridFlag = wkbcommon.SRIDFlag
+)
+
+// Read reads an arbitrary geometry from r.
+func Read(r io.Reader) (geom.T, error) {
+	return readGeometry(r, newDecodeOpts())
+}
+
+// decodeOpts holds the opt-in decoding behaviour configured via
+// DecoderOption.
+type decodeOpts struct {
+	chunkCoords int               // > 0 enables streaming decoding via wkbcommon.Stream*.
+	limits      *wkbcommon.Limits // size limits enforced for this decode.
+	acc         *wkbcommon.Accumulator
+}
+
+// newDecodeOpts returns the decodeOpts used by Read/Unmarshal: the
+// package-global MaxGeometryElements, with a fresh Accumulator so that a
+// single top-level decode's nested elements are counted together.
+func newDecodeOpts() decodeOpts {
+	return decodeOpts{limits: wkbcommon.NewLimits(), acc: &wkbcommon.Accumulator{}}
+}
+
+func readGeometry(r io.Reader, o decodeOpts) (geom.T, error) {
+	var wkbByteOrder byte
+	if err := binary.Read(r, binary.LittleEndian, &wkbByteOrder); err != nil {
+		return nil, err
+	}
+	var byteOrder binary.ByteOrder
+	switch wkbByteOrder {
+	case wkbcommon.XDRID:
+		byteOrder = wkbcommon.XDR
+	case wkbcommon.NDRID:
+		byteOrder = wkbcommon.NDR
+	default:
+		return nil, wkbcommon.ErrUnknownByteOrder(wkbByteOrder)
+	}
+
+	var ewkbType uint32
+	if err := binary.Read(r, byteOrder, &ewkbType); err != nil {
+		return nil, err
+	}
+
+	layout, err := layoutForFlags(ewkbType)
+	if err != nil {
+		return nil, err
+	}
+	baseType := wkbcommon.Type(ewkbType &^ (zFlag | mFlag | sridFlag))
+	stride := layout.Stride()
+
+	srid := 0
+	if ewkbType&sridFlag != 0 {
+		var u uint32
+		if err := binary.Read(r, byteOrder, &u); err != nil {
+			return nil, err
+		}
+		srid = int(u)
+	}
+
+	g, err := readBody(r, byteOrder, baseType, layout, stride, o)
+	if err != nil {
+		return nil, err
+	}
+	return setSRID(g, srid), nil
+}
+
+func readBody(r io.Reader, byteOrder binary.ByteOrder, baseType wkbcommon.Type, layout geom.Layout, stride int, o decodeOpts) (geom.T, error) {
+	switch baseType {
+	case wkbcommon.PointID:
+		flatCoords, err := wkbcommon.ReadFlatCoords0(r, byteOrder, stride)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case wkbcommon.LineStringID:
+		var flatCoords []float64
+		var err error
+		if o.chunkCoords > 0 {
+			flatCoords, err = wkbcommon.StreamFlatCoords1WithLimits(r, byteOrder, stride, o.chunkCoords, o.limits, o.acc)
+		} else {
+			flatCoords, err = wkbcommon.ReadFlatCoords1WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case wkbcommon.PolygonID:
+		var flatCoords []float64
+		var ends []int
+		var err error
+		if o.chunkCoords > 0 {
+			flatCoords, ends, err = wkbcommon.StreamFlatCoords2WithLimits(r, byteOrder, stride, o.chunkCoords, o.limits, o.acc)
+		} else {
+			flatCoords, ends, err = wkbcommon.ReadFlatCoords2WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+	case wkbcommon.TriangleID:
+		var flatCoords []float64
+		var ends []int
+		var err error
+		if o.chunkCoords > 0 {
+			flatCoords, ends, err = wkbcommon.StreamFlatCoords2WithLimits(r, byteOrder, stride, o.chunkCoords, o.limits, o.acc)
+		} else {
+			flatCoords, ends, err = wkbcommon.ReadFlatCoords2WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ends) != 1 {
+			return nil, wkbcommon.ErrUnsupportedType(baseType)
+		}
+		return geom.NewTriangle(layout).SetFlatCoords(flatCoords), nil
+	case wkbcommon.TINID:
+		flatCoordss, endss, err := wkbcommon.ReadFlatCoords3WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		if err != nil {
+			return nil, err
+		}
+		return tinFromFlatCoordss(layout, flatCoordss, endss)
+	case wkbcommon.MultiPointID, wkbcommon.MultiLineStringID, wkbcommon.MultiPolygonID, wkbcommon.GeometryCollectionID:
+		return readCollection(r, byteOrder, baseType, layout, o)
+	default:
+		return nil, wkbcommon.ErrUnsupportedType(baseType)
+	}
+}
+
+// readCollection reads the body of a MultiPoint, MultiLineString,
+// MultiPolygon or GeometryCollection, each of whose elements is encoded as a
+// full, independently byte-ordered and SRID-tagged EWKB geometry.
+func readCollection(r io.Reader, byteOrder binary.ByteOrder, baseType wkbcommon.Type, layout geom.Layout, o decodeOpts) (geom.T, error) {
+	level := 3
+	switch baseType {
+	case wkbcommon.MultiPointID:
+		level = 1
+	case wkbcommon.MultiLineStringID:
+		level = 2
+	}
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, err
+	}
+	if n > o.limits.PerLevel[level] {
+		return nil, wkbcommon.ErrGeometryTooLarge{Level: level, N: n, Limit: o.limits.PerLevel[level]}
+	}
+	switch baseType {
+	case wkbcommon.MultiPointID:
+		var flatCoords []float64
+		for i := 0; i < int(n); i++ {
+			g, err := readGeometry(r, o)
+			if err != nil {
+				return nil, err
+			}
+			p, ok := g.(*geom.Point)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Point{}}
+			}
+			if err := o.acc.Add(o.limits, 1, layout.Stride()); err != nil {
+				return nil, err
+			}
+			flatCoords = append(flatCoords, p.FlatCoords()...)
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	case wkbcommon.MultiLineStringID:
+		var flatCoords []float64
+		var ends []int
+		for i := 0; i < int(n); i++ {
+			g, err := readGeometry(r, o)
+			if err != nil {
+				return nil, err
+			}
+			ls, ok := g.(*geom.LineString)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.LineString{}}
+			}
+			flatCoords = append(flatCoords, ls.FlatCoords()...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+	case wkbcommon.MultiPolygonID:
+		var flatCoords []float64
+		var endss [][]int
+		for i := 0; i < int(n); i++ {
+			g, err := readGeometry(r, o)
+			if err != nil {
+				return nil, err
+			}
+			poly, ok := g.(*geom.Polygon)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Polygon{}}
+			}
+			offset := len(flatCoords)
+			flatCoords = append(flatCoords, poly.FlatCoords()...)
+			ends := make([]int, len(poly.Ends()))
+			for j, end := range poly.Ends() {
+				ends[j] = offset + end
+			}
+			endss = append(endss, ends)
+		}
+		return geom.NewMultiPolygonFlat(layout, flatCoords, endss), nil
+	default:
+		gc := geom.NewGeometryCollection()
+		for i := 0; i < int(n); i++ {
+			g, err := readGeometry(r, o)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := g.(*geom.Point); ok {
+				if err := o.acc.Add(o.limits, 1, g.Stride()); err != nil {
+					return nil, err
+				}
+			}
+			if err := gc.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return gc, nil
+	}
+}
+
+func setSRID(g geom.T, srid int) geom.T {
+	switch g := g.(type) {
+	case *geom.Point:
+		return g.SetSRID(srid)
+	case *geom.LineString:
+		return g.SetSRID(srid)
+	case *geom.Polygon:
+		return g.SetSRID(srid)
+	case *geom.Triangle:
+		return g.SetSRID(srid)
+	case *geom.TIN:
+		return g.SetSRID(srid)
+	case *geom.MultiPoint:
+		return g.SetSRID(srid)
+	case *geom.MultiLineString:
+		return g.SetSRID(srid)
+	case *geom.MultiPolygon:
+		return g.SetSRID(srid)
+	case *geom.GeometryCollection:
+		return g.SetSRID(srid)
+	default:
+		return g
+	}
+}
+
+func layoutForFlags(ewkbType uint32) (geom.Layout, error) {
+	z := ewkbType&zFlag != 0
+	m := ewkbType&mFlag != 0
+	switch {
+	case z && m:
+		return geom.XYZM, nil
+	case z:
+		return geom.XYZ, nil
+	case m:
+		return geom.XYM, nil
+	default:
+		return geom.XY, nil
+	}
+}
+
+func flagsForLayout(l geom.Layout) uint32 {
+	var flags uint32
+	if l.Z() {
+		flags |= zFlag
+	}
+	if l.M() {
+		flags |= mFlag
+	}
+	return flags
+}
+
+// tinFromFlatCoordss reassembles a TIN from the rings decoded by
+// ReadFlatCoords3, each of which is a single closed four-point ring. Vertices
+// that compare equal are shared, so a TIN round-tripped through EWKB
+// actually has the common vertex pool its doc comment promises.
+func tinFromFlatCoordss(layout geom.Layout, flatCoordss []float64, endss [][]int) (*geom.TIN, error) {
+	stride := layout.Stride()
+	vertices := make([]float64, 0, len(flatCoordss))
+	triangles := make([][3]int32, 0, len(endss))
+	seen := make(map[[4]float64]int32)
+	offset := 0
+	for _, ends := range endss {
+		if len(ends) != 1 {
+			return nil, wkbcommon.ErrUnsupportedType(wkbcommon.TriangleID)
+		}
+		ring := flatCoordss[offset:ends[0]]
+		offset = ends[0]
+		var idx [3]int32
+		for i := 0; i < 3; i++ {
+			point := ring[i*stride : (i+1)*stride]
+			var key [4]float64
+			copy(key[:], point)
+			vi, ok := seen[key]
+			if !ok {
+				vi = int32(len(vertices) / stride)
+				vertices = append(vertices, point...)
+				seen[key] = vi
+			}
+			idx[i] = vi
+		}
+		triangles = append(triangles, idx)
+	}
+	return geom.NewTIN(layout).SetVertices(vertices).SetTriangles(triangles), nil
+}
+
+// Write writes g to w using byte order bo, including g's SRID when non-zero.
+func Write(w io.Writer, bo binary.ByteOrder, g geom.T) error {
+	var wkbByteOrder byte
+	switch bo {
+	case wkbcommon.XDR:
+		wkbByteOrder = wkbcommon.XDRID
+	case wkbcommon.NDR:
+		wkbByteOrder = wkbcommon.NDRID
+	default:
+		return wkbcommon.ErrUnsupportedByteOrder{}
+	}
+	if err := binary.Write(w, binary.LittleEndian, wkbByteOrder); err != nil {
+		return err
+	}
+
+	var baseType uint32
+	switch g.(type) {
+	case *geom.Point:
+		baseType = wkbcommon.PointID
+	case *geom.LineString:
+		baseType = wkbcommon.LineStringID
+	case *geom.Polygon:
+		baseType = wkbcommon.PolygonID
+	case *geom.Triangle:
+		baseType = wkbcommon.TriangleID
+	case *geom.TIN:
+		baseType = wkbcommon.TINID
+	case *geom.MultiPoint:
+		baseType = wkbcommon.MultiPointID
+	case *geom.MultiLineString:
+		baseType = wkbcommon.MultiLineStringID
+	case *geom.MultiPolygon:
+		baseType = wkbcommon.MultiPolygonID
+	case *geom.GeometryCollection:
+		baseType = wkbcommon.GeometryCollectionID
+	default:
+		return wkbcommon.ErrUnsupportedType(0)
+	}
+
+	ewkbType := baseType | flagsForLayout(g.Layout())
+	if g.SRID() != 0 {
+		ewkbType |= sridFlag
+	}
+	if err := binary.Write(w, bo, ewkbType); err != nil {
+		return err
+	}
+	if g.SRID() != 0 {
+		if err := binary.Write(w, bo, uint32(g.SRID())); err != nil {
+			return err
+		}
+	}
+
+	switch g := g.(type) {
+	case *geom.Point:
+		return wkbcommon.WriteFlatCoords0(w, bo, g.FlatCoords())
+	case *geom.LineString:
+		return wkbcommon.WriteFlatCoords1(w, bo, g.FlatCoords(), g.Stride())
+	case *geom.Polygon:
+		return wkbcommon.WriteFlatCoords2(w, bo, g.FlatCoords(), g.Ends(), g.Stride())
+	case *geom.Triangle:
+		return wkbcommon.WriteFlatCoords2(w, bo, g.FlatCoords(), g.Ends(), g.Stride())
+	case *geom.TIN:
+		endss := make([][]int, g.NumTriangles())
+		var flatCoordss []float64
+		for i := 0; i < g.NumTriangles(); i++ {
+			tri := g.Triangle(i)
+			flatCoordss = append(flatCoordss, tri.FlatCoords()...)
+			endss[i] = []int{len(flatCoordss)}
+		}
+		return wkbcommon.WriteFlatCoords3(w, bo, flatCoordss, endss, g.Stride())
+	case *geom.MultiPoint:
+		if err := binary.Write(w, bo, uint32(g.NumPoints())); err != nil {
+			return err
+		}
+		stride := g.Stride()
+		flatCoords := g.FlatCoords()
+		for i := 0; i < g.NumPoints(); i++ {
+			p := geom.NewPointFlat(g.Layout(), flatCoords[i*stride:(i+1)*stride])
+			if err := Write(w, bo, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.MultiLineString:
+		if err := binary.Write(w, bo, uint32(g.NumLineStrings())); err != nil {
+			return err
+		}
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, end := range g.Ends() {
+			ls := geom.NewLineStringFlat(g.Layout(), flatCoords[offset:end])
+			if err := Write(w, bo, ls); err != nil {
+				return err
+			}
+			offset = end
+		}
+		return nil
+	case *geom.MultiPolygon:
+		if err := binary.Write(w, bo, uint32(g.NumPolygons())); err != nil {
+			return err
+		}
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, ends := range g.Endss() {
+			shiftedEnds := make([]int, len(ends))
+			for j, end := range ends {
+				shiftedEnds[j] = end - offset
+			}
+			length := 0
+			if len(ends) > 0 {
+				length = ends[len(ends)-1] - offset
+			}
+			poly := geom.NewPolygonFlat(g.Layout(), flatCoords[offset:offset+length], shiftedEnds)
+			if err := Write(w, bo, poly); err != nil {
+				return err
+			}
+			offset += length
+		}
+		return nil
+	case *geom.GeometryCollection:
+		if err := binary.Write(w, bo, uint32(g.NumGeoms())); err != nil {
+			return err
+		}
+		for _, sub := range g.Geoms() {
+			if err := Write(w, bo, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return wkbcommon.ErrUnsupportedType(0)
+	}
+}
+
+// A Decoder decodes geometries from an input stream.
+type Decoder struct {
+	r      io.Reader
+	opts   decodeOpts
+	strict bool
+}
+
+// A DecoderOption configures a Decoder.
+type DecoderOption func(*Decoder)
+
+// WithStreaming makes the Decoder read coordinates in fixed-size chunks of
+// chunkCoords coordinates, via wkbcommon.StreamFlatCoords1WithLimits/
+// StreamFlatCoords2WithLimits, instead of allocating and reading each
+// LineString's or Polygon ring's coordinates in a single pass. If
+// chunkCoords is <= 0, wkbcommon.DefaultChunkCoords is used. It composes
+// with WithLimits: the configured Limits are still enforced while
+// streaming. Existing callers of Read/Unmarshal are unaffected; this is
+// opt-in via NewDecoder.
+func WithStreaming(chunkCoords int) DecoderOption {
+	return func(d *Decoder) {
+		if chunkCoords <= 0 {
+			chunkCoords = wkbcommon.DefaultChunkCoords
+		}
+		d.opts.chunkCoords = chunkCoords
+	}
+}
+
+// WithLimits makes the Decoder enforce limits instead of the package-global
+// MaxGeometryElements, with no overall TotalCoords/TotalBytes cap unless
+// limits sets one.
+func WithLimits(limits *wkbcommon.Limits) DecoderOption {
+	return func(d *Decoder) {
+		d.opts.limits = limits
+	}
+}
+
+// NewDecoder returns a new Decoder that decodes from r.
+func NewDecoder(r io.Reader, opts ...DecoderOption) *Decoder {
+	d := &Decoder{r: r, opts: newDecodeOpts()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Strict enables (or, passed false, disables) strict decoding: when
+// enabled, Decode additionally runs wkbcommon.Validate against the bytes
+// it reads before returning a decoded geometry, rejecting OGC Simple
+// Features violations (unclosed rings, too-short rings or linestrings,
+// polygons with no rings, inconsistent SRID or Z/M within a collection)
+// that a plain decode would otherwise silently accept. It returns d for
+// chaining.
+func (d *Decoder) Strict(strict bool) *Decoder {
+	d.strict = strict
+	return d
+}
+
+// Decode decodes and returns a single geometry from d's underlying reader.
+// Each call starts a fresh Accumulator, so Limits' overall caps apply per
+// geometry decoded, not across the Decoder's lifetime.
+func (d *Decoder) Decode() (geom.T, error) {
+	o := d.opts
+	o.acc = &wkbcommon.Accumulator{}
+	if !d.strict {
+		return readGeometry(d.r, o)
+	}
+	// Validate walks the structure and discards coordinate data as it goes,
+	// buffering the raw bytes it reads via the TeeReader so that, once it
+	// passes, the real decode below can run against the buffer without
+	// re-reading d.r. This rejects malformed input before paying the cost
+	// of materializing any coordinates.
+	var buf bytes.Buffer
+	if err := wkbcommon.Validate(io.TeeReader(d.r, &buf), wkbcommon.ValidateOptions{EWKB: true}); err != nil {
+		return nil, err
+	}
+	return readGeometry(bytes.NewReader(buf.Bytes()), o)
+}
+
+// Marshal marshals an arbitrary geometry to a []byte.
+func Marshal(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
+	w := bytes.NewBuffer(nil)
+	if err := Write(w, byteOrder, g); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// Unmarshal unmarshals an arbitrary geometry from a []byte.
+func Unmarshal(data []byte) (geom.T, error) {
+	return Read(bytes.NewBuffer(data))
+}
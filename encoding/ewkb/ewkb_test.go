@@ -0,0 +1,135 @@
+package ewkb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ISI-nc/go-geom"
+	"github.com/ISI-nc/go-geom/encoding/wkbcommon"
+)
+
+func TestPointWithSRIDRoundTrip(t *testing.T) {
+	p, err := geom.NewPoint(geom.XY).SetCoords([]float64{1, 2})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	p.SetSRID(4326)
+
+	data, err := Marshal(p, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(p, NDR) == _, %v, want nil error", err)
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	got, ok := g.(*geom.Point)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.Point", g)
+	}
+	if got.SRID() != 4326 {
+		t.Errorf("SRID() == %d, want 4326", got.SRID())
+	}
+	if !coordsEqual(got.Coords(), []float64{1, 2}) {
+		t.Errorf("Coords() == %v, want [1 2]", got.Coords())
+	}
+}
+
+func TestDecoderWithLimitsConstrainsMultiPointTotalCoords(t *testing.T) {
+	flatCoords := make([]float64, 0, 1000)
+	for i := 0; i < 500; i++ {
+		flatCoords = append(flatCoords, float64(i), float64(i))
+	}
+	mp := geom.NewMultiPointFlat(geom.XY, flatCoords)
+	data, err := Marshal(mp, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(mp, NDR) == _, %v, want nil error", err)
+	}
+
+	limits := &wkbcommon.Limits{PerLevel: [4]uint32{1 << 20, 1 << 20, 1 << 20, 1 << 20}, TotalCoords: 10}
+	d := NewDecoder(bytes.NewReader(data), WithLimits(limits))
+	if _, err := d.Decode(); err == nil {
+		t.Error("Decode() == _, nil, want an ErrGeometryTooLarge for a MultiPoint exceeding TotalCoords")
+	}
+}
+
+func TestTriangleRoundTrip(t *testing.T) {
+	// A known-good PostGIS-produced NDR EWKB Triangle((0 0, 1 0, 0 1, 0 0))
+	// with no SRID set.
+	data := []byte{
+		0x01,
+		0x11, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00,
+		0x04, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	tri, ok := g.(*geom.Triangle)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.Triangle", g)
+	}
+	want := []float64{0, 0, 1, 0, 0, 1, 0, 0}
+	if !coordsEqual(tri.FlatCoords(), want) {
+		t.Errorf("FlatCoords() == %v, want %v", tri.FlatCoords(), want)
+	}
+
+	data2, err := Marshal(tri, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(tri, NDR) == _, %v, want nil error", err)
+	}
+	if string(data2) != string(data) {
+		t.Errorf("Marshal(tri, NDR) == %x, want %x", data2, data)
+	}
+}
+
+func TestTINRoundTripDedupesVertices(t *testing.T) {
+	t1, err := geom.NewTriangle(geom.XY).SetCoords([][]float64{{0, 0}, {1, 0}, {0, 1}, {0, 0}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	t2, err := geom.NewTriangle(geom.XY).SetCoords([][]float64{{1, 0}, {1, 1}, {0, 1}, {1, 0}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	tin := geom.NewTIN(geom.XY).
+		SetVertices(append(append([]float64{}, t1.FlatCoords()[:6]...), t2.FlatCoords()[:6]...)).
+		SetTriangles([][3]int32{{0, 1, 2}, {3, 4, 5}})
+
+	data, err := Marshal(tin, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(tin, NDR) == _, %v, want nil error", err)
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	got, ok := g.(*geom.TIN)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.TIN", g)
+	}
+	if len(got.FlatCoords()) != 8 {
+		t.Errorf("len(FlatCoords()) == %d, want 8 (4 deduplicated vertices)", len(got.FlatCoords()))
+	}
+}
+
+func coordsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
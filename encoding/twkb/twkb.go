@@ -0,0 +1,683 @@
+// Package twkb implements Tiny WKB (TWKB) encoding and decoding: a compact,
+// varint- and delta-encoded sibling of WKB intended for tiled/vector-tile
+// workloads where WKB's fixed-width, uncompressed doubles dominate payload
+// size.
+//
+// TWKB shares its geometry type codes with wkbcommon, but encodes
+// coordinates as zig-zag varints, scaled by a per-geometry decimal
+// precision and delta-encoded against the previous point. This package
+// supports XY and XYZ layouts; XYM and XYZM are not encodable, matching the
+// subset of the format actually produced by common TWKB writers.
+package twkb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/ISI-nc/go-geom"
+	"github.com/ISI-nc/go-geom/encoding/wkbcommon"
+)
+
+// TWKB metadata flag bits, following the header byte.
+const (
+	bboxFlag      = 0x01
+	sizeFlag      = 0x02
+	idListFlag    = 0x04
+	extendedFlag  = 0x08
+	emptyGeomFlag = 0x10
+)
+
+// Extended-dimensions flag bits, present only when extendedFlag is set.
+const (
+	hasZFlag = 0x01
+	hasMFlag = 0x02
+)
+
+// ErrUnsupportedLayout is returned when a geometry's layout cannot be
+// represented in TWKB.
+var ErrUnsupportedLayout = errors.New("twkb: unsupported layout")
+
+// ErrEmptyCollection reports a GeometryCollection whose layout cannot be
+// determined because it is empty. Marshal does not return it: it encodes an
+// empty GeometryCollection with the empty-geometry flag set.
+var ErrEmptyCollection = errors.New("twkb: cannot determine layout of empty collection")
+
+// ErrPrecisionOutOfRange is returned when Options.Precision cannot be
+// represented in the zigzag-encoded 4-bit header nibble.
+var ErrPrecisionOutOfRange = errors.New("twkb: precision out of range")
+
+// minPrecision and maxPrecision bound the values of Options.Precision that
+// round-trip through the zigzag-encoded 4-bit header nibble without
+// wrapping.
+const (
+	minPrecision = -8
+	maxPrecision = 7
+)
+
+// Options configures TWKB encoding.
+type Options struct {
+	// Precision is the number of decimal digits of precision to preserve.
+	// Coordinates are multiplied by 10^Precision, rounded to the nearest
+	// integer, and delta-encoded from there. Precision may be negative to
+	// round to a coarser grid. Precision is stored zigzag-encoded in a 4-bit
+	// header nibble, so it must be in the range [minPrecision, maxPrecision].
+	Precision int
+	// BBox, if true, includes a per-axis bounding box computed from the
+	// geometry.
+	BBox bool
+	// IDs, if non-empty, is encoded as the id list for the top-level
+	// elements of a multi-geometry or GeometryCollection. It must have the
+	// same length as the number of top-level elements.
+	IDs []int64
+}
+
+func zigzagEncode(n int64) uint64 {
+	return uint64((n << 1) ^ (n >> 63))
+}
+
+func zigzagDecode(n uint64) int64 {
+	return int64(n>>1) ^ -int64(n&1)
+}
+
+func writeVarint(buf *bytes.Buffer, v uint64) {
+	for v >= 0x80 {
+		buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	buf.WriteByte(byte(v))
+}
+
+func writeZigzagVarint(buf *bytes.Buffer, n int64) {
+	writeVarint(buf, zigzagEncode(n))
+}
+
+func readVarint(data []byte, i *int) (uint64, error) {
+	var v uint64
+	var shift uint
+	for {
+		if *i >= len(data) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		b := data[*i]
+		*i++
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v, nil
+		}
+		shift += 7
+		if shift > 63 {
+			return 0, errors.New("twkb: varint too large")
+		}
+	}
+}
+
+func readZigzagVarint(data []byte, i *int) (int64, error) {
+	v, err := readVarint(data, i)
+	if err != nil {
+		return 0, err
+	}
+	return zigzagDecode(v), nil
+}
+
+func baseTypeFor(g geom.T) (uint32, error) {
+	switch g.(type) {
+	case *geom.Point:
+		return wkbcommon.PointID, nil
+	case *geom.LineString:
+		return wkbcommon.LineStringID, nil
+	case *geom.Polygon:
+		return wkbcommon.PolygonID, nil
+	case *geom.MultiPoint:
+		return wkbcommon.MultiPointID, nil
+	case *geom.MultiLineString:
+		return wkbcommon.MultiLineStringID, nil
+	case *geom.MultiPolygon:
+		return wkbcommon.MultiPolygonID, nil
+	case *geom.GeometryCollection:
+		return wkbcommon.GeometryCollectionID, nil
+	default:
+		return 0, wkbcommon.ErrUnsupportedType(0)
+	}
+}
+
+// isEmpty reports whether g has no coordinates at all.
+func isEmpty(g geom.T) bool {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		return gc.NumGeoms() == 0
+	}
+	return len(g.FlatCoords()) == 0
+}
+
+// An encoder holds the running delta-encoding state for a single Marshal
+// call: the scale factor derived from Options.Precision and the previous
+// point written, shared across all parts of a multi-geometry. Each element
+// of a GeometryCollection is written by its own encoder, starting from a
+// zero previous point.
+type encoder struct {
+	buf       bytes.Buffer
+	scale     float64
+	precision int
+	prev      []int64 // previous point's scaled integer coordinates, one per dimension.
+}
+
+// Marshal marshals an arbitrary geometry to TWKB.
+func Marshal(g geom.T, opts Options) ([]byte, error) {
+	layout := g.Layout()
+	if layout == geom.NoLayout {
+		if gc, ok := g.(*geom.GeometryCollection); ok && gc.NumGeoms() > 0 {
+			layout = gc.Geoms()[0].Layout()
+		}
+	}
+	if layout != geom.NoLayout && layout != geom.XY && layout != geom.XYZ {
+		return nil, ErrUnsupportedLayout
+	}
+
+	baseType, err := baseTypeFor(g)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Precision < minPrecision || opts.Precision > maxPrecision {
+		return nil, ErrPrecisionOutOfRange
+	}
+
+	e := &encoder{scale: math.Pow(10, float64(opts.Precision)), precision: opts.Precision}
+	stride := layout.Stride()
+	e.prev = make([]int64, stride)
+
+	precisionZigzag := byte(zigzagEncode(int64(opts.Precision))) & 0x0f
+	header := byte(baseType&0x0f) | precisionZigzag<<4
+	e.buf.WriteByte(header)
+
+	var metadata byte
+	empty := isEmpty(g)
+	if opts.BBox && !empty {
+		metadata |= bboxFlag
+	}
+	if len(opts.IDs) > 0 && !empty {
+		metadata |= idListFlag
+	}
+	if layout == geom.XYZ {
+		metadata |= extendedFlag
+	}
+	if empty {
+		metadata |= emptyGeomFlag
+	}
+	e.buf.WriteByte(metadata)
+
+	if layout == geom.XYZ {
+		e.buf.WriteByte(hasZFlag)
+	}
+
+	if metadata&bboxFlag != 0 {
+		b := g.Bounds()
+		for i := 0; i < stride; i++ {
+			min := int64(math.Round(b.Min[i] * e.scale))
+			max := int64(math.Round(b.Max[i] * e.scale))
+			writeZigzagVarint(&e.buf, min)
+			writeZigzagVarint(&e.buf, max-min)
+		}
+	}
+
+	if empty {
+		return e.buf.Bytes(), nil
+	}
+
+	if metadata&idListFlag != 0 {
+		n, err := numTopLevelElements(g)
+		if err != nil {
+			return nil, err
+		}
+		if len(opts.IDs) != n {
+			return nil, errors.New("twkb: len(Options.IDs) does not match the number of top-level elements")
+		}
+		// The id list follows the element count, which writeGeom writes
+		// first, so encode the body separately and insert the ids after
+		// its leading count varint.
+		body := &encoder{scale: e.scale, precision: e.precision, prev: e.prev}
+		if err := body.writeGeom(g, stride); err != nil {
+			return nil, err
+		}
+		b := body.buf.Bytes()
+		i := 0
+		if _, err := readVarint(b, &i); err != nil {
+			return nil, err
+		}
+		e.buf.Write(b[:i])
+		for _, id := range opts.IDs {
+			writeZigzagVarint(&e.buf, id)
+		}
+		e.buf.Write(b[i:])
+		return e.buf.Bytes(), nil
+	}
+
+	if err := e.writeGeom(g, stride); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func numTopLevelElements(g geom.T) (int, error) {
+	switch g := g.(type) {
+	case *geom.MultiPoint:
+		return g.NumPoints(), nil
+	case *geom.MultiLineString:
+		return g.NumLineStrings(), nil
+	case *geom.MultiPolygon:
+		return g.NumPolygons(), nil
+	case *geom.GeometryCollection:
+		return g.NumGeoms(), nil
+	default:
+		return 0, errors.New("twkb: id list is only valid for multi-geometries and collections")
+	}
+}
+
+func (e *encoder) writePoint(flatCoords []float64, offset, stride int) {
+	for i := 0; i < stride; i++ {
+		v := int64(math.Round(flatCoords[offset+i] * e.scale))
+		writeZigzagVarint(&e.buf, v-e.prev[i])
+		e.prev[i] = v
+	}
+}
+
+func (e *encoder) writeRing(flatCoords []float64, start, end, stride int) {
+	n := (end - start) / stride
+	writeVarint(&e.buf, uint64(n))
+	for i := start; i < end; i += stride {
+		e.writePoint(flatCoords, i, stride)
+	}
+}
+
+func (e *encoder) writeGeom(g geom.T, stride int) error {
+	switch g := g.(type) {
+	case *geom.Point:
+		e.writePoint(g.FlatCoords(), 0, stride)
+		return nil
+	case *geom.LineString:
+		e.writeRing(g.FlatCoords(), 0, len(g.FlatCoords()), stride)
+		return nil
+	case *geom.Polygon:
+		ends := g.Ends()
+		writeVarint(&e.buf, uint64(len(ends)))
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, end := range ends {
+			e.writeRing(flatCoords, offset, end, stride)
+			offset = end
+		}
+		return nil
+	case *geom.MultiPoint:
+		flatCoords := g.FlatCoords()
+		n := g.NumPoints()
+		writeVarint(&e.buf, uint64(n))
+		for i := 0; i < n; i++ {
+			e.writePoint(flatCoords, i*stride, stride)
+		}
+		return nil
+	case *geom.MultiLineString:
+		ends := g.Ends()
+		writeVarint(&e.buf, uint64(len(ends)))
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, end := range ends {
+			e.writeRing(flatCoords, offset, end, stride)
+			offset = end
+		}
+		return nil
+	case *geom.MultiPolygon:
+		endss := g.Endss()
+		writeVarint(&e.buf, uint64(len(endss)))
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, ends := range endss {
+			writeVarint(&e.buf, uint64(len(ends)))
+			for _, end := range ends {
+				e.writeRing(flatCoords, offset, end, stride)
+				offset = end
+			}
+		}
+		return nil
+	case *geom.GeometryCollection:
+		// Each element of a GeometryCollection is a self-contained TWKB
+		// geometry with its own header and delta-encoding origin, mirroring
+		// decoder.readGeom, which is called once per element and starts a
+		// fresh previous-point state each time.
+		writeVarint(&e.buf, uint64(g.NumGeoms()))
+		for _, sub := range g.Geoms() {
+			subType, err := baseTypeFor(sub)
+			if err != nil {
+				return err
+			}
+			subPrecisionZigzag := byte(zigzagEncode(int64(e.precision))) & 0x0f
+			e.buf.WriteByte(byte(subType&0x0f) | subPrecisionZigzag<<4)
+			var subMetadata byte
+			if sub.Layout() == geom.XYZ {
+				subMetadata |= extendedFlag
+			}
+			if isEmpty(sub) {
+				subMetadata |= emptyGeomFlag
+			}
+			e.buf.WriteByte(subMetadata)
+			if sub.Layout() == geom.XYZ {
+				e.buf.WriteByte(hasZFlag)
+			}
+			if isEmpty(sub) {
+				continue
+			}
+			sube := &encoder{scale: e.scale, precision: e.precision, prev: make([]int64, sub.Stride())}
+			if err := sube.writeGeom(sub, sub.Stride()); err != nil {
+				return err
+			}
+			e.buf.Write(sube.buf.Bytes())
+		}
+		return nil
+	default:
+		return wkbcommon.ErrUnsupportedType(0)
+	}
+}
+
+// A decoder holds the running delta-decoding state for a single Unmarshal
+// call, mirroring encoder.
+type decoder struct {
+	data         []byte
+	i            int
+	prev         []int64
+	pendingCount *uint64 // the element count already consumed by peekCount, for readBody to reuse.
+}
+
+// Unmarshal unmarshals an arbitrary geometry from TWKB.
+func Unmarshal(data []byte) (geom.T, error) {
+	d := &decoder{data: data}
+	return d.readGeom()
+}
+
+// checkCount returns an error if n, an element count read from untrusted
+// input, exceeds wkbcommon.MaxGeometryElements at level, the same bound
+// wkb/ewkb enforce via ReadFlatCoordsN, so that a crafted count cannot
+// drive a make() of unbounded size.
+func checkCount(level int, n uint64) error {
+	if n > uint64(wkbcommon.MaxGeometryElements[level]) {
+		return wkbcommon.ErrGeometryTooLarge{Level: level, N: uint32(n), Limit: wkbcommon.MaxGeometryElements[level]}
+	}
+	return nil
+}
+
+// levelForMultiType returns the MaxGeometryElements level that bounds a
+// Multi*/GeometryCollection's top-level element count, mirroring the
+// levels wkb/ewkb check for the same geometry types.
+func levelForMultiType(baseType uint32) int {
+	switch baseType {
+	case wkbcommon.MultiPointID:
+		return 1
+	case wkbcommon.MultiLineStringID:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (d *decoder) readByte() (byte, error) {
+	if d.i >= len(d.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.i]
+	d.i++
+	return b, nil
+}
+
+func (d *decoder) readGeom() (geom.T, error) {
+	header, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	baseType := uint32(header & 0x0f)
+	precision := int(zigzagDecode(uint64(header >> 4)))
+	scale := math.Pow(10, float64(precision))
+
+	metadata, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	layout := geom.XY
+	if metadata&extendedFlag != 0 {
+		extended, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if extended&hasZFlag != 0 {
+			layout = geom.XYZ
+		}
+		if extended&hasMFlag != 0 {
+			return nil, ErrUnsupportedLayout
+		}
+	}
+	stride := layout.Stride()
+	d.prev = make([]int64, stride)
+
+	if metadata&bboxFlag != 0 {
+		for i := 0; i < stride; i++ {
+			if _, err := readZigzagVarint(d.data, &d.i); err != nil {
+				return nil, err
+			}
+			if _, err := readZigzagVarint(d.data, &d.i); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	empty := metadata&emptyGeomFlag != 0
+
+	var ids []int64
+	if metadata&idListFlag != 0 && !empty {
+		n, err := d.peekCount(baseType)
+		if err != nil {
+			return nil, err
+		}
+		ids = make([]int64, n)
+		for i := range ids {
+			v, err := readZigzagVarint(d.data, &d.i)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = v
+		}
+	}
+	_ = ids // the id list is consumed but not currently surfaced on geom.T
+
+	if empty {
+		return d.emptyGeom(baseType, layout)
+	}
+	return d.readBody(baseType, layout, stride, scale)
+}
+
+// peekCount reads the element count that precedes the coordinate data (the
+// same count readBody needs) so the id list, which comes first, can be
+// sized; it caches the count in d.pendingCount so readBody does not read it
+// again.
+func (d *decoder) peekCount(baseType uint32) (int, error) {
+	if baseType == wkbcommon.PointID || baseType == wkbcommon.LineStringID || baseType == wkbcommon.PolygonID {
+		return 0, errors.New("twkb: id list is only valid for multi-geometries and collections")
+	}
+	n, err := readVarint(d.data, &d.i)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkCount(levelForMultiType(baseType), n); err != nil {
+		return 0, err
+	}
+	d.pendingCount = &n
+	return int(n), nil
+}
+
+func (d *decoder) emptyGeom(baseType uint32, layout geom.Layout) (geom.T, error) {
+	switch baseType {
+	case wkbcommon.PointID:
+		return geom.NewPointFlat(layout, make([]float64, layout.Stride())), nil
+	case wkbcommon.LineStringID:
+		return geom.NewLineStringFlat(layout, nil), nil
+	case wkbcommon.PolygonID:
+		return geom.NewPolygonFlat(layout, nil, nil), nil
+	case wkbcommon.MultiPointID:
+		return geom.NewMultiPointFlat(layout, nil), nil
+	case wkbcommon.MultiLineStringID:
+		return geom.NewMultiLineStringFlat(layout, nil, nil), nil
+	case wkbcommon.MultiPolygonID:
+		return geom.NewMultiPolygonFlat(layout, nil, nil), nil
+	case wkbcommon.GeometryCollectionID:
+		return geom.NewGeometryCollection(), nil
+	default:
+		return nil, wkbcommon.ErrUnsupportedType(baseType)
+	}
+}
+
+func (d *decoder) readPoint(scale float64, stride int) []float64 {
+	coord := make([]float64, stride)
+	for i := 0; i < stride; i++ {
+		delta, _ := readZigzagVarint(d.data, &d.i)
+		d.prev[i] += delta
+		coord[i] = float64(d.prev[i]) / scale
+	}
+	return coord
+}
+
+func (d *decoder) readRing(scale float64, stride int) ([]float64, error) {
+	n, err := readVarint(d.data, &d.i)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(1, n); err != nil {
+		return nil, err
+	}
+	flatCoords := make([]float64, 0, int(n)*stride)
+	for i := 0; i < int(n); i++ {
+		flatCoords = append(flatCoords, d.readPoint(scale, stride)...)
+	}
+	return flatCoords, nil
+}
+
+func (d *decoder) readBody(baseType uint32, layout geom.Layout, stride int, scale float64) (geom.T, error) {
+	readCount := func() (uint64, error) {
+		if d.pendingCount != nil {
+			n := *d.pendingCount
+			d.pendingCount = nil
+			return n, nil
+		}
+		return readVarint(d.data, &d.i)
+	}
+
+	switch baseType {
+	case wkbcommon.PointID:
+		return geom.NewPointFlat(layout, d.readPoint(scale, stride)), nil
+	case wkbcommon.LineStringID:
+		flatCoords, err := d.readRing(scale, stride)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case wkbcommon.PolygonID:
+		n, err := readCount()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCount(2, n); err != nil {
+			return nil, err
+		}
+		var flatCoords []float64
+		var ends []int
+		for i := uint64(0); i < n; i++ {
+			ring, err := d.readRing(scale, stride)
+			if err != nil {
+				return nil, err
+			}
+			flatCoords = append(flatCoords, ring...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+	case wkbcommon.MultiPointID:
+		n, err := readCount()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCount(1, n); err != nil {
+			return nil, err
+		}
+		flatCoords := make([]float64, 0, int(n)*stride)
+		for i := uint64(0); i < n; i++ {
+			flatCoords = append(flatCoords, d.readPoint(scale, stride)...)
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	case wkbcommon.MultiLineStringID:
+		n, err := readCount()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCount(2, n); err != nil {
+			return nil, err
+		}
+		var flatCoords []float64
+		var ends []int
+		for i := uint64(0); i < n; i++ {
+			ring, err := d.readRing(scale, stride)
+			if err != nil {
+				return nil, err
+			}
+			flatCoords = append(flatCoords, ring...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+	case wkbcommon.MultiPolygonID:
+		n, err := readCount()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCount(3, n); err != nil {
+			return nil, err
+		}
+		var flatCoords []float64
+		var endss [][]int
+		for i := uint64(0); i < n; i++ {
+			nrings, err := readVarint(d.data, &d.i)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkCount(2, nrings); err != nil {
+				return nil, err
+			}
+			var ends []int
+			for j := uint64(0); j < nrings; j++ {
+				ring, err := d.readRing(scale, stride)
+				if err != nil {
+					return nil, err
+				}
+				flatCoords = append(flatCoords, ring...)
+				ends = append(ends, len(flatCoords))
+			}
+			endss = append(endss, ends)
+		}
+		return geom.NewMultiPolygonFlat(layout, flatCoords, endss), nil
+	case wkbcommon.GeometryCollectionID:
+		n, err := readCount()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCount(3, n); err != nil {
+			return nil, err
+		}
+		gc := geom.NewGeometryCollection()
+		for i := uint64(0); i < n; i++ {
+			sub, err := d.readGeom()
+			if err != nil {
+				return nil, err
+			}
+			if err := gc.Push(sub); err != nil {
+				return nil, err
+			}
+		}
+		return gc, nil
+	default:
+		return nil, wkbcommon.ErrUnsupportedType(baseType)
+	}
+}
@@ -0,0 +1,112 @@
+package twkb
+
+import (
+	"testing"
+
+	"github.com/ISI-nc/go-geom"
+)
+
+func TestPointRoundTrip(t *testing.T) {
+	p, err := geom.NewPoint(geom.XY).SetCoords([]float64{1.5, -2.25})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	data, err := Marshal(p, Options{Precision: 2})
+	if err != nil {
+		t.Fatalf("Marshal(p, {Precision: 2}) == _, %v, want nil error", err)
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	got, ok := g.(*geom.Point)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.Point", g)
+	}
+	want := []float64{1.5, -2.25}
+	for i, c := range want {
+		if got.Coords()[i] != c {
+			t.Errorf("Coords()[%d] == %v, want %v", i, got.Coords()[i], c)
+		}
+	}
+}
+
+func TestMultiPolygonRoundTrip(t *testing.T) {
+	poly1, err := geom.NewPolygon(geom.XY).SetCoords([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	poly2, err := geom.NewPolygon(geom.XY).SetCoords([][][]float64{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	flatCoords := append(append([]float64{}, poly1.FlatCoords()...), poly2.FlatCoords()...)
+	offset := len(poly1.FlatCoords())
+	ends2 := make([]int, len(poly2.Ends()))
+	for i, e := range poly2.Ends() {
+		ends2[i] = offset + e
+	}
+	mp := geom.NewMultiPolygonFlat(geom.XY, flatCoords, [][]int{poly1.Ends(), ends2})
+
+	data, err := Marshal(mp, Options{Precision: 0})
+	if err != nil {
+		t.Fatalf("Marshal(mp, {}) == _, %v, want nil error", err)
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	got, ok := g.(*geom.MultiPolygon)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.MultiPolygon", g)
+	}
+	if got.NumPolygons() != 2 {
+		t.Errorf("NumPolygons() == %d, want 2", got.NumPolygons())
+	}
+}
+
+// A crafted MultiPoint header followed by a huge varint count must be
+// rejected, not crash the process with makeslice: cap out of range.
+func TestUnmarshalRejectsHugeCount(t *testing.T) {
+	data := []byte{
+		0x04,                         // header: MultiPointID, precision 0
+		0x00,                         // metadata: no flags
+		0xff, 0xff, 0xff, 0xff, 0x0f, // varint count: 0xffffffff
+	}
+	if _, err := Unmarshal(data); err == nil {
+		t.Error("Unmarshal(...) == _, nil, want an error for an oversized count")
+	}
+}
+
+func TestUnmarshalRejectsHugeRingCount(t *testing.T) {
+	data := []byte{
+		0x03,                         // header: PolygonID, precision 0
+		0x00,                         // metadata: no flags
+		0xff, 0xff, 0xff, 0xff, 0x0f, // varint ring count: 0xffffffff
+	}
+	if _, err := Unmarshal(data); err == nil {
+		t.Error("Unmarshal(...) == _, nil, want an error for an oversized ring count")
+	}
+}
+
+func TestMarshalRejectsPrecisionOutOfRange(t *testing.T) {
+	p, err := geom.NewPoint(geom.XY).SetCoords([]float64{1.23456789, 0})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	if _, err := Marshal(p, Options{Precision: 8}); err == nil {
+		t.Error("Marshal(p, {Precision: 8}) == _, nil, want an error (precision nibble would wrap)")
+	}
+	if _, err := Marshal(p, Options{Precision: -9}); err == nil {
+		t.Error("Marshal(p, {Precision: -9}) == _, nil, want an error (precision nibble would wrap)")
+	}
+}
+
+func TestUnmarshalTruncatedInput(t *testing.T) {
+	if _, err := Unmarshal(nil); err == nil {
+		t.Error("Unmarshal(nil) == _, nil, want an error")
+	}
+	if _, err := Unmarshal([]byte{0x01}); err == nil {
+		t.Error("Unmarshal([]byte{0x01}) == _, nil, want an error")
+	}
+}
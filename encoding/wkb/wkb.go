@@ -0,0 +1,530 @@
+// Package wkb implements Well Known Binary encoding and decoding.
+package wkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/ISI-nc/go-geom"
+	"github.com/ISI-nc/go-geom/encoding/wkbcommon"
+)
+
+// Read reads an arbitrary geometry from r.
+func Read(r io.Reader) (geom.T, error) {
+	return readGeometry(r, newDecodeOpts())
+}
+
+// decodeOpts holds the opt-in decoding behaviour configured via
+// DecoderOption.
+type decodeOpts struct {
+	chunkCoords int               // > 0 enables streaming decoding via wkbcommon.Stream*.
+	limits      *wkbcommon.Limits // size limits enforced for this decode.
+	acc         *wkbcommon.Accumulator
+}
+
+// newDecodeOpts returns the decodeOpts used by Read/Unmarshal: the
+// package-global MaxGeometryElements, with a fresh Accumulator so that a
+// single top-level decode's nested elements are counted together.
+func newDecodeOpts() decodeOpts {
+	return decodeOpts{limits: wkbcommon.NewLimits(), acc: &wkbcommon.Accumulator{}}
+}
+
+func readGeometry(r io.Reader, o decodeOpts) (geom.T, error) {
+	var wkbByteOrder byte
+	if err := binary.Read(r, binary.LittleEndian, &wkbByteOrder); err != nil {
+		return nil, err
+	}
+	var byteOrder binary.ByteOrder
+	switch wkbByteOrder {
+	case wkbcommon.XDRID:
+		byteOrder = wkbcommon.XDR
+	case wkbcommon.NDRID:
+		byteOrder = wkbcommon.NDR
+	default:
+		return nil, wkbcommon.ErrUnknownByteOrder(wkbByteOrder)
+	}
+
+	var wkbGeometryType uint32
+	if err := binary.Read(r, byteOrder, &wkbGeometryType); err != nil {
+		return nil, err
+	}
+
+	t := wkbcommon.Type(wkbGeometryType)
+	layout, err := layoutForType(t)
+	if err != nil {
+		return nil, err
+	}
+	baseType := t % 1000
+	stride := layout.Stride()
+
+	switch baseType {
+	case wkbcommon.PointID:
+		flatCoords, err := wkbcommon.ReadFlatCoords0(r, byteOrder, stride)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case wkbcommon.LineStringID:
+		var flatCoords []float64
+		var err error
+		if o.chunkCoords > 0 {
+			flatCoords, err = wkbcommon.StreamFlatCoords1WithLimits(r, byteOrder, stride, o.chunkCoords, o.limits, o.acc)
+		} else {
+			flatCoords, err = wkbcommon.ReadFlatCoords1WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case wkbcommon.PolygonID:
+		var flatCoords []float64
+		var ends []int
+		var err error
+		if o.chunkCoords > 0 {
+			flatCoords, ends, err = wkbcommon.StreamFlatCoords2WithLimits(r, byteOrder, stride, o.chunkCoords, o.limits, o.acc)
+		} else {
+			flatCoords, ends, err = wkbcommon.ReadFlatCoords2WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+	case wkbcommon.MultiPointID:
+		flatCoords, err := readMultiPointFlatCoords(r, byteOrder, o, stride)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	case wkbcommon.MultiLineStringID:
+		flatCoords, ends, err := readMultiLineStringFlatCoords(r, byteOrder, o, stride)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+	case wkbcommon.MultiPolygonID:
+		flatCoords, endss, err := readMultiPolygonFlatCoords(r, byteOrder, o, stride)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPolygonFlat(layout, flatCoords, endss), nil
+	case wkbcommon.TriangleID:
+		var flatCoords []float64
+		var ends []int
+		var err error
+		if o.chunkCoords > 0 {
+			flatCoords, ends, err = wkbcommon.StreamFlatCoords2WithLimits(r, byteOrder, stride, o.chunkCoords, o.limits, o.acc)
+		} else {
+			flatCoords, ends, err = wkbcommon.ReadFlatCoords2WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ends) != 1 {
+			return nil, wkbcommon.ErrUnsupportedType(t)
+		}
+		return geom.NewTriangle(layout).SetFlatCoords(flatCoords), nil
+	case wkbcommon.TINID:
+		flatCoordss, endss, err := wkbcommon.ReadFlatCoords3WithLimits(r, byteOrder, stride, o.limits, o.acc)
+		if err != nil {
+			return nil, err
+		}
+		return tinFromFlatCoordss(layout, flatCoordss, endss)
+	case wkbcommon.GeometryCollectionID:
+		var n uint32
+		if err := binary.Read(r, byteOrder, &n); err != nil {
+			return nil, err
+		}
+		if n > o.limits.PerLevel[3] {
+			return nil, wkbcommon.ErrGeometryTooLarge{Level: 3, N: n, Limit: o.limits.PerLevel[3]}
+		}
+		gc := geom.NewGeometryCollection()
+		for i := 0; i < int(n); i++ {
+			g, err := readGeometry(r, o)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := g.(*geom.Point); ok {
+				if err := o.acc.Add(o.limits, 1, g.Stride()); err != nil {
+					return nil, err
+				}
+			}
+			if err := gc.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return gc, nil
+	default:
+		return nil, wkbcommon.ErrUnsupportedType(t)
+	}
+}
+
+// readMultiPointFlatCoords reads the Points of a MultiPoint, each of which is
+// encoded as a full, independently byte-ordered WKB Point.
+func readMultiPointFlatCoords(r io.Reader, byteOrder binary.ByteOrder, o decodeOpts, stride int) ([]float64, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, err
+	}
+	if n > o.limits.PerLevel[1] {
+		return nil, wkbcommon.ErrGeometryTooLarge{Level: 1, N: n, Limit: o.limits.PerLevel[1]}
+	}
+	flatCoords := make([]float64, 0, int(n)*stride)
+	for i := 0; i < int(n); i++ {
+		g, err := readGeometry(r, o)
+		if err != nil {
+			return nil, err
+		}
+		p, ok := g.(*geom.Point)
+		if !ok {
+			return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Point{}}
+		}
+		if err := o.acc.Add(o.limits, 1, stride); err != nil {
+			return nil, err
+		}
+		flatCoords = append(flatCoords, p.FlatCoords()...)
+	}
+	return flatCoords, nil
+}
+
+// readMultiLineStringFlatCoords reads the LineStrings of a MultiLineString,
+// each of which is encoded as a full, independently byte-ordered WKB
+// LineString.
+func readMultiLineStringFlatCoords(r io.Reader, byteOrder binary.ByteOrder, o decodeOpts, stride int) ([]float64, []int, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, nil, err
+	}
+	if n > o.limits.PerLevel[2] {
+		return nil, nil, wkbcommon.ErrGeometryTooLarge{Level: 2, N: n, Limit: o.limits.PerLevel[2]}
+	}
+	var flatCoords []float64
+	var ends []int
+	for i := 0; i < int(n); i++ {
+		g, err := readGeometry(r, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		ls, ok := g.(*geom.LineString)
+		if !ok {
+			return nil, nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.LineString{}}
+		}
+		flatCoords = append(flatCoords, ls.FlatCoords()...)
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends, nil
+}
+
+// readMultiPolygonFlatCoords reads the Polygons of a MultiPolygon, each of
+// which is encoded as a full, independently byte-ordered WKB Polygon.
+func readMultiPolygonFlatCoords(r io.Reader, byteOrder binary.ByteOrder, o decodeOpts, stride int) ([]float64, [][]int, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, nil, err
+	}
+	if n > o.limits.PerLevel[3] {
+		return nil, nil, wkbcommon.ErrGeometryTooLarge{Level: 3, N: n, Limit: o.limits.PerLevel[3]}
+	}
+	var flatCoords []float64
+	var endss [][]int
+	for i := 0; i < int(n); i++ {
+		g, err := readGeometry(r, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		poly, ok := g.(*geom.Polygon)
+		if !ok {
+			return nil, nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Polygon{}}
+		}
+		offset := len(flatCoords)
+		flatCoords = append(flatCoords, poly.FlatCoords()...)
+		ends := make([]int, len(poly.Ends()))
+		for j, end := range poly.Ends() {
+			ends[j] = offset + end
+		}
+		endss = append(endss, ends)
+	}
+	return flatCoords, endss, nil
+}
+
+// layoutForType returns the Layout corresponding to a WKB type code,
+// including its Z/M/ZM variant.
+func layoutForType(t wkbcommon.Type) (geom.Layout, error) {
+	switch t / 1000 {
+	case 0:
+		return geom.XY, nil
+	case 1:
+		return geom.XYZ, nil
+	case 2:
+		return geom.XYM, nil
+	case 3:
+		return geom.XYZM, nil
+	default:
+		return geom.NoLayout, wkbcommon.ErrUnknownType(t)
+	}
+}
+
+// typeCodeForT returns the base WKB type code and the dimensional offset for
+// g's layout.
+func typeCodeForT(g geom.T, base uint32) uint32 {
+	switch g.Layout() {
+	case geom.XYZ:
+		return base + wkbcommon.ZOffset
+	case geom.XYM:
+		return base + wkbcommon.MOffset
+	case geom.XYZM:
+		return base + wkbcommon.ZMOffset
+	default:
+		return base
+	}
+}
+
+// tinFromFlatCoordss reassembles a TIN from the rings decoded by
+// ReadFlatCoords3, each of which is a single closed four-point ring. Vertices
+// that compare equal are shared, so a TIN round-tripped through WKB actually
+// has the common vertex pool its doc comment promises.
+func tinFromFlatCoordss(layout geom.Layout, flatCoordss []float64, endss [][]int) (*geom.TIN, error) {
+	stride := layout.Stride()
+	vertices := make([]float64, 0, len(flatCoordss))
+	triangles := make([][3]int32, 0, len(endss))
+	seen := make(map[[4]float64]int32)
+	offset := 0
+	for _, ends := range endss {
+		if len(ends) != 1 {
+			return nil, wkbcommon.ErrUnsupportedType(wkbcommon.TriangleID)
+		}
+		ring := flatCoordss[offset:ends[0]]
+		offset = ends[0]
+		// A closed ring of 4 points encodes 3 distinct vertices.
+		var idx [3]int32
+		for i := 0; i < 3; i++ {
+			point := ring[i*stride : (i+1)*stride]
+			var key [4]float64
+			copy(key[:], point)
+			vi, ok := seen[key]
+			if !ok {
+				vi = int32(len(vertices) / stride)
+				vertices = append(vertices, point...)
+				seen[key] = vi
+			}
+			idx[i] = vi
+		}
+		triangles = append(triangles, idx)
+	}
+	return geom.NewTIN(layout).SetVertices(vertices).SetTriangles(triangles), nil
+}
+
+// Write writes g to w using byte order bo.
+func Write(w io.Writer, bo binary.ByteOrder, g geom.T) error {
+	var wkbByteOrder byte
+	switch bo {
+	case wkbcommon.XDR:
+		wkbByteOrder = wkbcommon.XDRID
+	case wkbcommon.NDR:
+		wkbByteOrder = wkbcommon.NDRID
+	default:
+		return wkbcommon.ErrUnsupportedByteOrder{}
+	}
+	if err := binary.Write(w, binary.LittleEndian, wkbByteOrder); err != nil {
+		return err
+	}
+
+	switch g := g.(type) {
+	case *geom.Point:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.PointID)); err != nil {
+			return err
+		}
+		return wkbcommon.WriteFlatCoords0(w, bo, g.FlatCoords())
+	case *geom.LineString:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.LineStringID)); err != nil {
+			return err
+		}
+		return wkbcommon.WriteFlatCoords1(w, bo, g.FlatCoords(), g.Stride())
+	case *geom.Polygon:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.PolygonID)); err != nil {
+			return err
+		}
+		return wkbcommon.WriteFlatCoords2(w, bo, g.FlatCoords(), g.Ends(), g.Stride())
+	case *geom.Triangle:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.TriangleID)); err != nil {
+			return err
+		}
+		return wkbcommon.WriteFlatCoords2(w, bo, g.FlatCoords(), g.Ends(), g.Stride())
+	case *geom.MultiPoint:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.MultiPointID)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, bo, uint32(g.NumPoints())); err != nil {
+			return err
+		}
+		stride := g.Stride()
+		flatCoords := g.FlatCoords()
+		for i := 0; i < g.NumPoints(); i++ {
+			p := geom.NewPointFlat(g.Layout(), flatCoords[i*stride:(i+1)*stride])
+			if err := Write(w, bo, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.MultiLineString:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.MultiLineStringID)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, bo, uint32(g.NumLineStrings())); err != nil {
+			return err
+		}
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, end := range g.Ends() {
+			ls := geom.NewLineStringFlat(g.Layout(), flatCoords[offset:end])
+			if err := Write(w, bo, ls); err != nil {
+				return err
+			}
+			offset = end
+		}
+		return nil
+	case *geom.MultiPolygon:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.MultiPolygonID)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, bo, uint32(g.NumPolygons())); err != nil {
+			return err
+		}
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, ends := range g.Endss() {
+			shiftedEnds := make([]int, len(ends))
+			for j, end := range ends {
+				shiftedEnds[j] = end - offset
+			}
+			length := 0
+			if len(ends) > 0 {
+				length = ends[len(ends)-1] - offset
+			}
+			poly := geom.NewPolygonFlat(g.Layout(), flatCoords[offset:offset+length], shiftedEnds)
+			if err := Write(w, bo, poly); err != nil {
+				return err
+			}
+			offset += length
+		}
+		return nil
+	case *geom.GeometryCollection:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.GeometryCollectionID)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, bo, uint32(g.NumGeoms())); err != nil {
+			return err
+		}
+		for _, sub := range g.Geoms() {
+			if err := Write(w, bo, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.TIN:
+		if err := binary.Write(w, bo, typeCodeForT(g, wkbcommon.TINID)); err != nil {
+			return err
+		}
+		endss := make([][]int, g.NumTriangles())
+		var flatCoordss []float64
+		for i := 0; i < g.NumTriangles(); i++ {
+			tri := g.Triangle(i)
+			flatCoordss = append(flatCoordss, tri.FlatCoords()...)
+			endss[i] = []int{len(flatCoordss)}
+		}
+		return wkbcommon.WriteFlatCoords3(w, bo, flatCoordss, endss, g.Stride())
+	default:
+		return wkbcommon.ErrUnsupportedType(0)
+	}
+}
+
+// A Decoder decodes geometries from an input stream.
+type Decoder struct {
+	r      io.Reader
+	opts   decodeOpts
+	strict bool
+}
+
+// A DecoderOption configures a Decoder.
+type DecoderOption func(*Decoder)
+
+// WithStreaming makes the Decoder read coordinates in fixed-size chunks of
+// chunkCoords coordinates, via wkbcommon.StreamFlatCoords1WithLimits/
+// StreamFlatCoords2WithLimits, instead of allocating and reading each
+// LineString's or Polygon ring's coordinates in a single pass. If
+// chunkCoords is <= 0, wkbcommon.DefaultChunkCoords is used. It composes
+// with WithLimits: the configured Limits are still enforced while
+// streaming. Existing callers of Read/Unmarshal are unaffected; this is
+// opt-in via NewDecoder.
+func WithStreaming(chunkCoords int) DecoderOption {
+	return func(d *Decoder) {
+		if chunkCoords <= 0 {
+			chunkCoords = wkbcommon.DefaultChunkCoords
+		}
+		d.opts.chunkCoords = chunkCoords
+	}
+}
+
+// WithLimits makes the Decoder enforce limits instead of the package-global
+// MaxGeometryElements, with no overall TotalCoords/TotalBytes cap unless
+// limits sets one.
+func WithLimits(limits *wkbcommon.Limits) DecoderOption {
+	return func(d *Decoder) {
+		d.opts.limits = limits
+	}
+}
+
+// NewDecoder returns a new Decoder that decodes from r.
+func NewDecoder(r io.Reader, opts ...DecoderOption) *Decoder {
+	d := &Decoder{r: r, opts: newDecodeOpts()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Strict enables (or, passed false, disables) strict decoding: when
+// enabled, Decode additionally runs wkbcommon.Validate against the bytes
+// it reads before returning a decoded geometry, rejecting OGC Simple
+// Features violations (unclosed rings, too-short rings or linestrings,
+// polygons with no rings) that a plain decode would otherwise silently
+// accept. It returns d for chaining.
+func (d *Decoder) Strict(strict bool) *Decoder {
+	d.strict = strict
+	return d
+}
+
+// Decode decodes and returns a single geometry from d's underlying reader.
+// Each call starts a fresh Accumulator, so Limits' overall caps apply per
+// geometry decoded, not across the Decoder's lifetime.
+func (d *Decoder) Decode() (geom.T, error) {
+	o := d.opts
+	o.acc = &wkbcommon.Accumulator{}
+	if !d.strict {
+		return readGeometry(d.r, o)
+	}
+	// Validate walks the structure and discards coordinate data as it goes,
+	// buffering the raw bytes it reads via the TeeReader so that, once it
+	// passes, the real decode below can run against the buffer without
+	// re-reading d.r. This rejects malformed input before paying the cost
+	// of materializing any coordinates.
+	var buf bytes.Buffer
+	if err := wkbcommon.Validate(io.TeeReader(d.r, &buf), wkbcommon.ValidateOptions{}); err != nil {
+		return nil, err
+	}
+	return readGeometry(bytes.NewReader(buf.Bytes()), o)
+}
+
+// Marshal marshals an arbitrary geometry to a []byte.
+func Marshal(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
+	w := bytes.NewBuffer(nil)
+	if err := Write(w, byteOrder, g); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// Unmarshal unmarshals an arbitrary geometry from a []byte.
+func Unmarshal(data []byte) (geom.T, error) {
+	return Read(bytes.NewBuffer(data))
+}
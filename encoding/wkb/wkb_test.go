@@ -0,0 +1,236 @@
+package wkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ISI-nc/go-geom"
+	"github.com/ISI-nc/go-geom/encoding/wkbcommon"
+)
+
+func coordsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPointRoundTrip(t *testing.T) {
+	// 0101000000000000000000F03F0000000000000040, a known-good
+	// PostGIS-produced NDR WKB Point(1 2).
+	data := []byte{
+		0x01,
+		0x01, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40,
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	p, ok := g.(*geom.Point)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.Point", g)
+	}
+	want := []float64{1, 2}
+	if !coordsEqual(p.Coords(), want) {
+		t.Errorf("Unmarshal(...) == %v, want %v", p.Coords(), want)
+	}
+
+	data2, err := Marshal(p, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(%v, NDR) == _, %v, want nil error", p, err)
+	}
+	if string(data2) != string(data) {
+		t.Errorf("Marshal(%v, NDR) == %x, want %x", p, data2, data)
+	}
+}
+
+func TestDecoderWithLimitsConstrainsMultiPolygon(t *testing.T) {
+	poly, err := geom.NewPolygon(geom.XY).SetCoords([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	mp := geom.NewMultiPolygonFlat(geom.XY, poly.FlatCoords(), [][]int{poly.Ends()})
+	data, err := Marshal(mp, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(mp, NDR) == _, %v, want nil error", err)
+	}
+
+	limits := &wkbcommon.Limits{PerLevel: [4]uint32{0, 1 << 20, 1 << 15, 0}}
+	d := NewDecoder(bytes.NewReader(data), WithLimits(limits))
+	if _, err := d.Decode(); err == nil {
+		t.Error("Decode() == _, nil, want an ErrGeometryTooLarge for a MultiPolygon exceeding a zero PerLevel[3] limit")
+	}
+}
+
+func TestDecoderWithLimitsConstrainsTriangle(t *testing.T) {
+	tri, err := geom.NewTriangle(geom.XY).SetCoords([][]float64{{0, 0}, {1, 0}, {0, 1}, {0, 0}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	data, err := Marshal(tri, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(tri, NDR) == _, %v, want nil error", err)
+	}
+
+	limits := &wkbcommon.Limits{PerLevel: [4]uint32{0, 2, 1 << 20, 1 << 20}}
+	d := NewDecoder(bytes.NewReader(data), WithLimits(limits))
+	if _, err := d.Decode(); err == nil {
+		t.Error("Decode() == _, nil, want an ErrGeometryTooLarge for a Triangle ring exceeding a PerLevel[1]=2 limit")
+	}
+}
+
+func TestDecoderWithLimitsConstrainsMultiPointTotalCoords(t *testing.T) {
+	flatCoords := make([]float64, 0, 1000)
+	for i := 0; i < 500; i++ {
+		flatCoords = append(flatCoords, float64(i), float64(i))
+	}
+	mp := geom.NewMultiPointFlat(geom.XY, flatCoords)
+	data, err := Marshal(mp, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(mp, NDR) == _, %v, want nil error", err)
+	}
+
+	limits := &wkbcommon.Limits{PerLevel: [4]uint32{1 << 20, 1 << 20, 1 << 20, 1 << 20}, TotalCoords: 10}
+	d := NewDecoder(bytes.NewReader(data), WithLimits(limits))
+	if _, err := d.Decode(); err == nil {
+		t.Error("Decode() == _, nil, want an ErrGeometryTooLarge for a MultiPoint exceeding TotalCoords")
+	}
+}
+
+func TestTriangleRoundTrip(t *testing.T) {
+	// A known-good PostGIS-produced NDR WKB Triangle((0 0, 1 0, 0 1, 0 0)).
+	data := []byte{
+		0x01,
+		0x11, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00,
+		0x04, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	tri, ok := g.(*geom.Triangle)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.Triangle", g)
+	}
+	want := []float64{0, 0, 1, 0, 0, 1, 0, 0}
+	if !coordsEqual(tri.FlatCoords(), want) {
+		t.Errorf("FlatCoords() == %v, want %v", tri.FlatCoords(), want)
+	}
+
+	data2, err := Marshal(tri, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(tri, NDR) == _, %v, want nil error", err)
+	}
+	if string(data2) != string(data) {
+		t.Errorf("Marshal(tri, NDR) == %x, want %x", data2, data)
+	}
+}
+
+func TestTINRoundTripDedupesVertices(t *testing.T) {
+	// Two triangles sharing an edge: (0,0)-(1,0)-(0,1) and (1,0)-(1,1)-(0,1).
+	t1, err := geom.NewTriangle(geom.XY).SetCoords([][]float64{{0, 0}, {1, 0}, {0, 1}, {0, 0}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	t2, err := geom.NewTriangle(geom.XY).SetCoords([][]float64{{1, 0}, {1, 1}, {0, 1}, {1, 0}})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	tin := geom.NewTIN(geom.XY).
+		SetVertices(append(append([]float64{}, t1.FlatCoords()[:6]...), t2.FlatCoords()[:6]...)).
+		SetTriangles([][3]int32{{0, 1, 2}, {3, 4, 5}})
+
+	data, err := Marshal(tin, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(tin, NDR) == _, %v, want nil error", err)
+	}
+	g, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want nil error", err)
+	}
+	got, ok := g.(*geom.TIN)
+	if !ok {
+		t.Fatalf("Unmarshal(...) == %T, want *geom.TIN", g)
+	}
+	if got.NumTriangles() != 2 {
+		t.Fatalf("NumTriangles() == %d, want 2", got.NumTriangles())
+	}
+	// 6 vertices in, but only 4 distinct, so the decoded vertex pool should
+	// be deduplicated down to 4 points (8 flat coordinates).
+	if len(got.FlatCoords()) != 8 {
+		t.Errorf("len(FlatCoords()) == %d, want 8 (4 deduplicated vertices)", len(got.FlatCoords()))
+	}
+}
+
+func TestDecoderStrictRejectsTriangleWithMoreThanOneRing(t *testing.T) {
+	// A Triangle (OGC: exactly one ring) encoded with two rings.
+	var buf bytes.Buffer
+	buf.WriteByte(0x01)                                             // NDR
+	binary.Write(&buf, wkbcommon.NDR, uint32(wkbcommon.TriangleID)) // Triangle
+	binary.Write(&buf, wkbcommon.NDR, uint32(2))                    // 2 rings
+	binary.Write(&buf, wkbcommon.NDR, uint32(4))                    // ring 0: 4 points
+	binary.Write(&buf, wkbcommon.NDR, []float64{0, 0, 1, 0, 0, 1, 0, 0})
+	binary.Write(&buf, wkbcommon.NDR, uint32(4)) // ring 1: 4 points
+	binary.Write(&buf, wkbcommon.NDR, []float64{2, 2, 3, 2, 2, 3, 2, 2})
+
+	d := NewDecoder(bytes.NewReader(buf.Bytes())).Strict(true)
+	if _, err := d.Decode(); err == nil {
+		t.Error("Decode() == _, nil, want a ValidationError for a Triangle with more than one ring")
+	}
+}
+
+func TestDecoderStrictRejectsUnclosedRing(t *testing.T) {
+	// A LineString (valid on its own) wrapped as a Polygon with a ring that
+	// isn't closed: first point (0,0), last point (1,1).
+	var buf bytes.Buffer
+	buf.WriteByte(0x01)                                            // NDR
+	binary.Write(&buf, wkbcommon.NDR, uint32(wkbcommon.PolygonID)) // Polygon
+	binary.Write(&buf, wkbcommon.NDR, uint32(1))                   // 1 ring
+	binary.Write(&buf, wkbcommon.NDR, uint32(4))                   // 4 points
+	binary.Write(&buf, wkbcommon.NDR, []float64{0, 0, 1, 0, 0, 1, 1, 1})
+
+	d := NewDecoder(bytes.NewReader(buf.Bytes())).Strict(true)
+	if _, err := d.Decode(); err == nil {
+		t.Error("Decode() == _, nil, want a ValidationError for an unclosed ring")
+	}
+}
+
+func TestDecoderStrictAcceptsValidGeometry(t *testing.T) {
+	p, err := geom.NewPoint(geom.XY).SetCoords([]float64{1, 2})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	data, err := Marshal(p, wkbcommon.NDR)
+	if err != nil {
+		t.Fatalf("Marshal(p, NDR) == _, %v, want nil error", err)
+	}
+	d := NewDecoder(bytes.NewReader(data)).Strict(true)
+	g, err := d.Decode()
+	if err != nil {
+		t.Fatalf("Decode() == _, %v, want nil error", err)
+	}
+	got, ok := g.(*geom.Point)
+	if !ok {
+		t.Fatalf("Decode() == %T, want *geom.Point", g)
+	}
+	if !coordsEqual(got.Coords(), []float64{1, 2}) {
+		t.Errorf("Coords() == %v, want [1 2]", got.Coords())
+	}
+}
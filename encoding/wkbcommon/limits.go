@@ -0,0 +1,48 @@
+package wkbcommon
+
+// A Limits configures the size limits enforced by a single decoder,
+// replacing the package-global MaxGeometryElements. PerLevel mirrors
+// MaxGeometryElements: the maximum number of elements allowed at each
+// nesting level. TotalCoords and TotalBytes, if non-zero, additionally cap
+// the overall number of coordinates and coordinate bytes read across an
+// entire decode, so that a geometry whose per-level counts are each legal
+// but whose sum is pathological (e.g. a MultiPolygon with many
+// legal-but-large rings) is still rejected.
+type Limits struct {
+	PerLevel    [4]uint32
+	TotalCoords uint64
+	TotalBytes  uint64
+}
+
+// NewLimits returns a new Limits with PerLevel seeded from the current
+// MaxGeometryElements and no overall caps.
+func NewLimits() *Limits {
+	return &Limits{PerLevel: MaxGeometryElements}
+}
+
+// An Accumulator tracks the number of coordinates and coordinate bytes read
+// so far across a single decode, so that nested ReadFlatCoords1WithLimits
+// and ReadFlatCoords2WithLimits calls can enforce a Limits' overall caps.
+// Its zero value is ready to use.
+type Accumulator struct {
+	Coords uint64
+	Bytes  uint64
+}
+
+// Add records n additional stride-wide coordinates and returns an error if
+// doing so would exceed limits' overall caps. Callers that read coordinates
+// without going through ReadFlatCoordsNWithLimits or StreamFlatCoordsNWithLimits
+// (e.g. a MultiPoint reading each Point individually) must call Add
+// themselves so that limits.TotalCoords/TotalBytes still see those
+// coordinates.
+func (a *Accumulator) Add(limits *Limits, n uint32, stride int) error {
+	a.Coords += uint64(n)
+	if limits.TotalCoords > 0 && a.Coords > limits.TotalCoords {
+		return ErrGeometryTooLarge{Level: 1, N: uint32(a.Coords), Limit: uint32(limits.TotalCoords)}
+	}
+	a.Bytes += uint64(n) * uint64(stride) * 8
+	if limits.TotalBytes > 0 && a.Bytes > limits.TotalBytes {
+		return ErrGeometryTooLarge{Level: 1, N: uint32(a.Bytes), Limit: uint32(limits.TotalBytes)}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package wkbcommon
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// DefaultChunkCoords is the default number of coordinates read per chunk by
+// the Stream* functions when no chunk size is given.
+const DefaultChunkCoords = 4096
+
+// A CoordReader reads successive chunks of coordinates from an underlying
+// source. NextChunk reads into buf, returning the number of coordinates
+// read. It returns an error, possibly io.EOF, if fewer than len(buf)
+// coordinates could be read.
+type CoordReader interface {
+	NextChunk(buf []float64) (n int, err error)
+}
+
+// An ioCoordReader is a CoordReader that decodes coordinates from an
+// io.Reader in a given byte order, reusing a scratch buffer across calls to
+// NextChunk to avoid allocating on every chunk.
+type ioCoordReader struct {
+	r         io.Reader
+	byteOrder binary.ByteOrder
+	scratch   []byte
+}
+
+// NewCoordReader returns a CoordReader that reads coordinates from r in
+// byteOrder.
+func NewCoordReader(r io.Reader, byteOrder binary.ByteOrder) CoordReader {
+	return &ioCoordReader{r: r, byteOrder: byteOrder}
+}
+
+func (cr *ioCoordReader) NextChunk(buf []float64) (int, error) {
+	need := len(buf) * 8
+	if cap(cr.scratch) < need {
+		cr.scratch = make([]byte, need)
+	}
+	b := cr.scratch[:need]
+	n, err := io.ReadFull(cr.r, b)
+	full := n / 8
+	for i := 0; i < full; i++ {
+		bits := cr.byteOrder.Uint64(b[i*8 : i*8+8])
+		buf[i] = math.Float64frombits(bits)
+	}
+	return full, err
+}
+
+// StreamFlatCoords1 reads flat coordinates 1 (a count followed by that many
+// stride-wide coordinates) the same way as ReadFlatCoords1, but reads the
+// coordinates in fixed-size chunks of chunkCoords coordinates at a time via
+// io.ReadFull into a pre-sized result slice, instead of allocating the whole
+// slice and reading it with a single binary.Read. If chunkCoords is <= 0,
+// DefaultChunkCoords is used. This bounds the size of any single read and
+// avoids the append-in-a-loop pattern of ReadFlatCoords2 for very large
+// geometries. It enforces the package-global MaxGeometryElements; it is a
+// thin wrapper around StreamFlatCoords1WithLimits, kept for callers that
+// have not migrated to per-decoder Limits.
+func StreamFlatCoords1(r io.Reader, byteOrder binary.ByteOrder, stride, chunkCoords int) ([]float64, error) {
+	return StreamFlatCoords1WithLimits(r, byteOrder, stride, chunkCoords, NewLimits(), &Accumulator{})
+}
+
+// StreamFlatCoords1WithLimits reads flat coordinates 1 the same way as
+// StreamFlatCoords1, but enforces limits instead of the package-global
+// MaxGeometryElements, and records the coordinates it reads in acc, so that
+// WithStreaming composes with WithLimits instead of silently ignoring it.
+func StreamFlatCoords1WithLimits(r io.Reader, byteOrder binary.ByteOrder, stride, chunkCoords int, limits *Limits, acc *Accumulator) ([]float64, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, err
+	}
+	if n > limits.PerLevel[1] {
+		return nil, ErrGeometryTooLarge{Level: 1, N: n, Limit: limits.PerLevel[1]}
+	}
+	if err := acc.Add(limits, n, stride); err != nil {
+		return nil, err
+	}
+	if chunkCoords <= 0 {
+		chunkCoords = DefaultChunkCoords
+	}
+	total := int(n) * stride
+	flatCoords := make([]float64, total)
+	cr := NewCoordReader(r, byteOrder)
+	for offset := 0; offset < total; {
+		chunk := chunkCoords
+		if offset+chunk > total {
+			chunk = total - offset
+		}
+		read, err := cr.NextChunk(flatCoords[offset : offset+chunk])
+		offset += read
+		if err != nil {
+			return nil, err
+		}
+	}
+	return flatCoords, nil
+}
+
+// StreamFlatCoords2 reads flat coordinates 2 the same way as
+// ReadFlatCoords2, but reads the outer ring count first, pre-sizes the
+// resulting ends slice, and streams each ring's coordinates via
+// StreamFlatCoords1. It enforces the package-global MaxGeometryElements; it
+// is a thin wrapper around StreamFlatCoords2WithLimits, kept for callers
+// that have not migrated to per-decoder Limits.
+func StreamFlatCoords2(r io.Reader, byteOrder binary.ByteOrder, stride, chunkCoords int) ([]float64, []int, error) {
+	return StreamFlatCoords2WithLimits(r, byteOrder, stride, chunkCoords, NewLimits(), &Accumulator{})
+}
+
+// StreamFlatCoords2WithLimits reads flat coordinates 2 the same way as
+// StreamFlatCoords2, but enforces limits and accumulates into acc, sharing
+// both with the nested StreamFlatCoords1WithLimits calls it makes for each
+// ring, so that WithStreaming composes with WithLimits instead of silently
+// ignoring it.
+func StreamFlatCoords2WithLimits(r io.Reader, byteOrder binary.ByteOrder, stride, chunkCoords int, limits *Limits, acc *Accumulator) ([]float64, []int, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, nil, err
+	}
+	if n > limits.PerLevel[2] {
+		return nil, nil, ErrGeometryTooLarge{Level: 2, N: n, Limit: limits.PerLevel[2]}
+	}
+	ends := make([]int, 0, n)
+	var flatCoords []float64
+	for i := 0; i < int(n); i++ {
+		ring, err := StreamFlatCoords1WithLimits(r, byteOrder, stride, chunkCoords, limits, acc)
+		if err != nil {
+			return nil, nil, err
+		}
+		flatCoords = append(flatCoords, ring...)
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends, nil
+}
@@ -0,0 +1,37 @@
+package wkbcommon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestStreamFlatCoords1WithLimitsEnforcesLimit(t *testing.T) {
+	var buf bytes.Buffer
+	binary.Write(&buf, NDR, uint32(10))
+	limits := &Limits{PerLevel: [4]uint32{0, 5, 0, 0}}
+	_, err := StreamFlatCoords1WithLimits(&buf, NDR, 2, 0, limits, &Accumulator{})
+	if _, ok := err.(ErrGeometryTooLarge); !ok {
+		t.Errorf("StreamFlatCoords1WithLimits(...) == _, %v, want ErrGeometryTooLarge", err)
+	}
+}
+
+func TestStreamFlatCoords1WithLimitsWithinLimit(t *testing.T) {
+	var buf bytes.Buffer
+	binary.Write(&buf, NDR, uint32(2))
+	binary.Write(&buf, NDR, []float64{1, 2, 3, 4})
+	limits := &Limits{PerLevel: [4]uint32{0, 5, 0, 0}}
+	got, err := StreamFlatCoords1WithLimits(&buf, NDR, 2, 0, limits, &Accumulator{})
+	if err != nil {
+		t.Fatalf("StreamFlatCoords1WithLimits(...) == _, %v, want nil error", err)
+	}
+	want := []float64{1, 2, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("StreamFlatCoords1WithLimits(...) == %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("StreamFlatCoords1WithLimits(...)[%d] == %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package wkbcommon
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EWKB dimension and SRID flag bits, ORed into the high byte of the WKB
+// type code. Defined here, rather than in package ewkb, so that Validate
+// can parse an EWKB header without a dependency on package ewkb (which
+// itself depends on wkbcommon).
+const (
+	ZFlag    = 0x80000000
+	MFlag    = 0x40000000
+	SRIDFlag = 0x20000000
+)
+
+// OGC Simple Features rule violations returned, wrapped in a
+// ValidationError, by Validate.
+var (
+	ErrRingNotClosed      = errors.New("ring is not closed")
+	ErrRingTooShort       = errors.New("ring has fewer than 4 points")
+	ErrLineStringTooShort = errors.New("linestring has fewer than 2 points")
+	ErrPolygonHasNoRings  = errors.New("polygon has no rings")
+	ErrInconsistentSRID   = errors.New("child SRID does not match its collection's SRID")
+	ErrInconsistentLayout = errors.New("child Z/M dimensions do not match its collection's")
+)
+
+// A ValidationError reports an OGC Simple Features rule violation found by
+// Validate. Path identifies the offending geometry within the overall
+// structure, e.g. "MultiPolygon[2].Polygon.Ring[0]".
+type ValidationError struct {
+	Path string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("wkb: invalid geometry at %s: %s", e.Path, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateOptions configures Validate.
+type ValidateOptions struct {
+	// EWKB indicates that r contains EWKB (type flag bits and an optional
+	// SRID) rather than plain WKB (type plus a 1000/2000/3000 dimension
+	// offset).
+	EWKB bool
+}
+
+// Validate walks the WKB (or, if opts.EWKB, EWKB) structure read from r
+// without materializing any coordinate values, skipping coordinate data
+// with io.CopyN(io.Discard, ...) instead of allocating it, and returns a
+// *ValidationError at the first OGC Simple Features rule it finds
+// violated: an unclosed ring, a ring with fewer than 4 points, a
+// LineString with fewer than 2 points, a Polygon with no rings, a
+// collection whose children do not all share its Z/M dimensionality, or
+// an EWKB collection whose children declare a different SRID than it
+// does. It is intended as a cheap pre-flight check before a full decode.
+func Validate(r io.Reader, opts ValidateOptions) error {
+	_, _, _, err := validateGeometry(r, opts, "", false, false, false, 0)
+	return err
+}
+
+func validateGeometry(r io.Reader, opts ValidateOptions, path string, hasParent, parentZ, parentM bool, parentSRID int) (z, m bool, srid int, err error) {
+	var byteOrderByte byte
+	if err := binary.Read(r, binary.LittleEndian, &byteOrderByte); err != nil {
+		return false, false, 0, err
+	}
+	var byteOrder binary.ByteOrder
+	switch byteOrderByte {
+	case XDRID:
+		byteOrder = XDR
+	case NDRID:
+		byteOrder = NDR
+	default:
+		return false, false, 0, ErrUnknownByteOrder(byteOrderByte)
+	}
+
+	var rawType uint32
+	if err := binary.Read(r, byteOrder, &rawType); err != nil {
+		return false, false, 0, err
+	}
+
+	var baseType Type
+	if opts.EWKB {
+		z = rawType&ZFlag != 0
+		m = rawType&MFlag != 0
+		baseType = Type(rawType &^ uint32(ZFlag|MFlag|SRIDFlag))
+		if rawType&SRIDFlag != 0 {
+			var u uint32
+			if err := binary.Read(r, byteOrder, &u); err != nil {
+				return false, false, 0, err
+			}
+			srid = int(u)
+		}
+	} else {
+		t := Type(rawType)
+		baseType = t % 1000
+		switch t / 1000 {
+		case 1:
+			z = true
+		case 2:
+			m = true
+		case 3:
+			z, m = true, true
+		}
+	}
+
+	label := typeName(baseType)
+	path = joinPath(path, label)
+
+	if hasParent {
+		if z != parentZ || m != parentM {
+			return z, m, srid, &ValidationError{Path: path, Err: ErrInconsistentLayout}
+		}
+		if opts.EWKB && srid != 0 && parentSRID != 0 && srid != parentSRID {
+			return z, m, srid, &ValidationError{Path: path, Err: ErrInconsistentSRID}
+		}
+	}
+	effectiveSRID := srid
+	if effectiveSRID == 0 {
+		effectiveSRID = parentSRID
+	}
+
+	stride := 2
+	if z {
+		stride++
+	}
+	if m {
+		stride++
+	}
+
+	switch baseType {
+	case PointID:
+		if err := skipCoords(r, 1, stride); err != nil {
+			return z, m, srid, err
+		}
+	case LineStringID:
+		n, err := readCount(r, byteOrder, 1, path)
+		if err != nil {
+			return z, m, srid, err
+		}
+		if n < 2 {
+			return z, m, srid, &ValidationError{Path: path, Err: ErrLineStringTooShort}
+		}
+		if err := skipCoords(r, int(n), stride); err != nil {
+			return z, m, srid, err
+		}
+	case PolygonID, TriangleID:
+		n, err := readCount(r, byteOrder, 2, path)
+		if err != nil {
+			return z, m, srid, err
+		}
+		if baseType == PolygonID && n < 1 {
+			return z, m, srid, &ValidationError{Path: path, Err: ErrPolygonHasNoRings}
+		}
+		if baseType == TriangleID && n != 1 {
+			return z, m, srid, &ValidationError{Path: path, Err: ErrUnsupportedType(TriangleID)}
+		}
+		for i := 0; i < int(n); i++ {
+			if err := validateRing(r, byteOrder, stride, fmt.Sprintf("%s.Ring[%d]", path, i)); err != nil {
+				return z, m, srid, err
+			}
+		}
+	case TINID:
+		n, err := readCount(r, byteOrder, 3, path)
+		if err != nil {
+			return z, m, srid, err
+		}
+		for i := 0; i < int(n); i++ {
+			triPath := fmt.Sprintf("%s.Triangle[%d]", path, i)
+			rn, err := readCount(r, byteOrder, 2, triPath)
+			if err != nil {
+				return z, m, srid, err
+			}
+			if rn != 1 {
+				return z, m, srid, &ValidationError{Path: triPath, Err: ErrUnsupportedType(TriangleID)}
+			}
+			if err := validateRing(r, byteOrder, stride, triPath+".Ring[0]"); err != nil {
+				return z, m, srid, err
+			}
+		}
+	case MultiPointID, MultiLineStringID, MultiPolygonID, GeometryCollectionID:
+		level := 1
+		switch baseType {
+		case MultiLineStringID:
+			level = 2
+		case MultiPolygonID, GeometryCollectionID:
+			level = 3
+		}
+		n, err := readCount(r, byteOrder, level, path)
+		if err != nil {
+			return z, m, srid, err
+		}
+		for i := 0; i < int(n); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			if _, _, _, err := validateGeometry(r, opts, childPath, true, z, m, effectiveSRID); err != nil {
+				return z, m, srid, err
+			}
+		}
+	default:
+		return z, m, srid, ErrUnsupportedType(baseType)
+	}
+
+	return z, m, srid, nil
+}
+
+func joinPath(path, label string) string {
+	if path == "" {
+		return label
+	}
+	return path + "." + label
+}
+
+func typeName(t Type) string {
+	switch t {
+	case PointID:
+		return "Point"
+	case LineStringID:
+		return "LineString"
+	case PolygonID:
+		return "Polygon"
+	case MultiPointID:
+		return "MultiPoint"
+	case MultiLineStringID:
+		return "MultiLineString"
+	case MultiPolygonID:
+		return "MultiPolygon"
+	case GeometryCollectionID:
+		return "GeometryCollection"
+	case PolyhedralSurfaceID:
+		return "PolyhedralSurface"
+	case TINID:
+		return "TIN"
+	case TriangleID:
+		return "Triangle"
+	default:
+		return fmt.Sprintf("Type(%d)", t)
+	}
+}
+
+// readCount reads an element count and checks it against
+// MaxGeometryElements[level], the same bound ReadFlatCoordsN enforces at
+// that level, so that a corrupt count cannot drive Validate into an
+// excessively long loop.
+func readCount(r io.Reader, byteOrder binary.ByteOrder, level int, path string) (uint32, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return 0, err
+	}
+	if n > MaxGeometryElements[level] {
+		return 0, &ValidationError{Path: path, Err: ErrGeometryTooLarge{Level: level, N: n, Limit: MaxGeometryElements[level]}}
+	}
+	return n, nil
+}
+
+// skipCoords discards n stride-wide coordinates from r without
+// materializing them.
+func skipCoords(r io.Reader, n, stride int) error {
+	_, err := io.CopyN(io.Discard, r, int64(n)*int64(stride)*8)
+	return err
+}
+
+// validateRing reads and discards a ring's points, checking that it has
+// at least 4 points and that its first and last points are equal, without
+// materializing the points in between.
+func validateRing(r io.Reader, byteOrder binary.ByteOrder, stride int, path string) error {
+	n, err := readCount(r, byteOrder, 1, path)
+	if err != nil {
+		return err
+	}
+	if n < 4 {
+		return &ValidationError{Path: path, Err: ErrRingTooShort}
+	}
+	first := make([]float64, stride)
+	if err := binary.Read(r, byteOrder, &first); err != nil {
+		return err
+	}
+	if n > 2 {
+		if err := skipCoords(r, int(n)-2, stride); err != nil {
+			return err
+		}
+	}
+	last := make([]float64, stride)
+	if err := binary.Read(r, byteOrder, &last); err != nil {
+		return err
+	}
+	for i := range first {
+		if first[i] != last[i] {
+			return &ValidationError{Path: path, Err: ErrRingNotClosed}
+		}
+	}
+	return nil
+}
@@ -98,6 +98,24 @@ const (
 	TriangleID           = 17
 )
 
+// Dimension feature offsets, added to a base geometry type ID to signal the
+// presence of Z and/or M coordinates, per the OGC/PostGIS WKB extension.
+const (
+	ZOffset  = 1000
+	MOffset  = 2000
+	ZMOffset = 3000
+)
+
+// TIN and Triangle dimensional variants.
+const (
+	TinZ       = TINID + ZOffset
+	TinM       = TINID + MOffset
+	TinZM      = TINID + ZMOffset
+	TriangleZ  = TriangleID + ZOffset
+	TriangleM  = TriangleID + MOffset
+	TriangleZM = TriangleID + ZMOffset
+)
+
 // ReadFlatCoords0 reads flat coordinates 0.
 func ReadFlatCoords0(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, error) {
 	coord := make([]float64, stride)
@@ -107,14 +125,29 @@ func ReadFlatCoords0(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]flo
 	return coord, nil
 }
 
-// ReadFlatCoords1 reads flat coordinates 1.
+// ReadFlatCoords1 reads flat coordinates 1, enforcing the package-global
+// MaxGeometryElements. It is a thin wrapper around
+// ReadFlatCoords1WithLimits, kept for callers that have not migrated to
+// per-decoder Limits.
 func ReadFlatCoords1(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, error) {
+	return ReadFlatCoords1WithLimits(r, byteOrder, stride, NewLimits(), &Accumulator{})
+}
+
+// ReadFlatCoords1WithLimits reads flat coordinates 1 the same way as
+// ReadFlatCoords1, but enforces limits instead of the package-global
+// MaxGeometryElements, and records the coordinates it reads in acc so that
+// callers can enforce limits' overall TotalCoords/TotalBytes caps across
+// nested calls.
+func ReadFlatCoords1WithLimits(r io.Reader, byteOrder binary.ByteOrder, stride int, limits *Limits, acc *Accumulator) ([]float64, error) {
 	var n uint32
 	if err := binary.Read(r, byteOrder, &n); err != nil {
 		return nil, err
 	}
-	if n > MaxGeometryElements[1] {
-		return nil, ErrGeometryTooLarge{Level: 1, N: n, Limit: MaxGeometryElements[1]}
+	if n > limits.PerLevel[1] {
+		return nil, ErrGeometryTooLarge{Level: 1, N: n, Limit: limits.PerLevel[1]}
+	}
+	if err := acc.Add(limits, n, stride); err != nil {
+		return nil, err
 	}
 	flatCoords := make([]float64, int(n)*stride)
 	if err := binary.Read(r, byteOrder, &flatCoords); err != nil {
@@ -123,19 +156,32 @@ func ReadFlatCoords1(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]flo
 	return flatCoords, nil
 }
 
-// ReadFlatCoords2 reads flat coordinates 2.
+// ReadFlatCoords2 reads flat coordinates 2, enforcing the package-global
+// MaxGeometryElements. It is a thin wrapper around
+// ReadFlatCoords2WithLimits, kept for callers that have not migrated to
+// per-decoder Limits.
 func ReadFlatCoords2(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, []int, error) {
+	return ReadFlatCoords2WithLimits(r, byteOrder, stride, NewLimits(), &Accumulator{})
+}
+
+// ReadFlatCoords2WithLimits reads flat coordinates 2 the same way as
+// ReadFlatCoords2, but enforces limits and accumulates into acc, sharing
+// both with the nested ReadFlatCoords1WithLimits calls it makes for each
+// ring so that, e.g., a Polygon whose individual rings are each within
+// limits.PerLevel[1] but whose total coordinate count exceeds
+// limits.TotalCoords is still rejected.
+func ReadFlatCoords2WithLimits(r io.Reader, byteOrder binary.ByteOrder, stride int, limits *Limits, acc *Accumulator) ([]float64, []int, error) {
 	var n uint32
 	if err := binary.Read(r, byteOrder, &n); err != nil {
 		return nil, nil, err
 	}
-	if n > MaxGeometryElements[2] {
-		return nil, nil, ErrGeometryTooLarge{Level: 2, N: n, Limit: MaxGeometryElements[2]}
+	if n > limits.PerLevel[2] {
+		return nil, nil, ErrGeometryTooLarge{Level: 2, N: n, Limit: limits.PerLevel[2]}
 	}
 	var flatCoordss []float64
 	var ends []int
 	for i := 0; i < int(n); i++ {
-		flatCoords, err := ReadFlatCoords1(r, byteOrder, stride)
+		flatCoords, err := ReadFlatCoords1WithLimits(r, byteOrder, stride, limits, acc)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -145,6 +191,49 @@ func ReadFlatCoords2(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]flo
 	return flatCoordss, ends, nil
 }
 
+// ReadFlatCoords3 reads flat coordinates 3: a count of sub-geometries, each
+// of which is itself encoded as flat coordinates 2 (a count of rings
+// followed by each ring's flat coordinates). This is used to decode a TIN as
+// a sequence of single-ring Triangles. The Triangles carry no byte order or
+// type header of their own, unlike the Polygons of a MultiPolygon as the
+// wkb and ewkb packages read them. It enforces the package-global
+// MaxGeometryElements; it is a thin wrapper around
+// ReadFlatCoords3WithLimits, kept for callers that have not migrated to
+// per-decoder Limits.
+func ReadFlatCoords3(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, [][]int, error) {
+	return ReadFlatCoords3WithLimits(r, byteOrder, stride, NewLimits(), &Accumulator{})
+}
+
+// ReadFlatCoords3WithLimits reads flat coordinates 3 the same way as
+// ReadFlatCoords3, but enforces limits and accumulates into acc, sharing
+// both with the nested ReadFlatCoords2WithLimits calls it makes for each
+// sub-geometry.
+func ReadFlatCoords3WithLimits(r io.Reader, byteOrder binary.ByteOrder, stride int, limits *Limits, acc *Accumulator) ([]float64, [][]int, error) {
+	var n uint32
+	if err := binary.Read(r, byteOrder, &n); err != nil {
+		return nil, nil, err
+	}
+	if n > limits.PerLevel[3] {
+		return nil, nil, ErrGeometryTooLarge{Level: 3, N: n, Limit: limits.PerLevel[3]}
+	}
+	var flatCoordss []float64
+	var endss [][]int
+	for i := 0; i < int(n); i++ {
+		flatCoords, ends, err := ReadFlatCoords2WithLimits(r, byteOrder, stride, limits, acc)
+		if err != nil {
+			return nil, nil, err
+		}
+		offset := len(flatCoordss)
+		flatCoordss = append(flatCoordss, flatCoords...)
+		shiftedEnds := make([]int, len(ends))
+		for j, end := range ends {
+			shiftedEnds[j] = offset + end
+		}
+		endss = append(endss, shiftedEnds)
+	}
+	return flatCoordss, endss, nil
+}
+
 // WriteFlatCoords0 writes flat coordinates 0.
 func WriteFlatCoords0(w io.Writer, byteOrder binary.ByteOrder, coord []float64) error {
 	return binary.Write(w, byteOrder, coord)
@@ -172,3 +261,27 @@ func WriteFlatCoords2(w io.Writer, byteOrder binary.ByteOrder, flatCoords []floa
 	}
 	return nil
 }
+
+// WriteFlatCoords3 writes flat coordinates 3, the inverse of
+// ReadFlatCoords3.
+func WriteFlatCoords3(w io.Writer, byteOrder binary.ByteOrder, flatCoordss []float64, endss [][]int, stride int) error {
+	if err := binary.Write(w, byteOrder, uint32(len(endss))); err != nil {
+		return err
+	}
+	offset := 0
+	for _, ends := range endss {
+		shiftedEnds := make([]int, len(ends))
+		for j, end := range ends {
+			shiftedEnds[j] = end - offset
+		}
+		length := 0
+		if len(ends) > 0 {
+			length = ends[len(ends)-1] - offset
+		}
+		if err := WriteFlatCoords2(w, byteOrder, flatCoordss[offset:offset+length], shiftedEnds, stride); err != nil {
+			return err
+		}
+		offset += length
+	}
+	return nil
+}
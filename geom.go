@@ -0,0 +1,140 @@
+// Package geom implements efficient geometry types for geospatial applications.
+package geom
+
+import "math"
+
+// A Layout describes the meaning of an N-dimensional coordinate.
+type Layout int
+
+// Layouts.
+const (
+	NoLayout Layout = iota
+	XY
+	XYZ
+	XYM
+	XYZM
+)
+
+// Stride returns l's number of coordinates per point.
+func (l Layout) Stride() int {
+	switch l {
+	case XY:
+		return 2
+	case XYZ, XYM:
+		return 3
+	case XYZM:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Z reports whether l has a Z coordinate.
+func (l Layout) Z() bool {
+	return l == XYZ || l == XYZM
+}
+
+// M reports whether l has an M coordinate.
+func (l Layout) M() bool {
+	return l == XYM || l == XYZM
+}
+
+// String returns a human-readable representation of l.
+func (l Layout) String() string {
+	switch l {
+	case XY:
+		return "XY"
+	case XYZ:
+		return "XYZ"
+	case XYM:
+		return "XYM"
+	case XYZM:
+		return "XYZM"
+	default:
+		return "NoLayout"
+	}
+}
+
+// A T is a generic geometry.
+type T interface {
+	Bounds() *Bounds
+	SRID() int
+	Layout() Layout
+	Stride() int
+	FlatCoords() []float64
+	Ends() []int
+	Endss() [][]int
+}
+
+// A Bounds represents a bounding box.
+type Bounds struct {
+	Layout Layout
+	Min    []float64
+	Max    []float64
+}
+
+// NewBounds returns a new, empty Bounds with layout l.
+func NewBounds(l Layout) *Bounds {
+	stride := l.Stride()
+	min := make([]float64, stride)
+	max := make([]float64, stride)
+	for i := 0; i < stride; i++ {
+		min[i] = math.Inf(1)
+		max[i] = math.Inf(-1)
+	}
+	return &Bounds{Layout: l, Min: min, Max: max}
+}
+
+// extendFlatCoords extends b to include the coordinates in
+// flatCoords[start:end] and returns b.
+func (b *Bounds) extendFlatCoords(flatCoords []float64, start, end, stride int) *Bounds {
+	for i := start; i < end; i += stride {
+		for j := 0; j < stride; j++ {
+			if flatCoords[i+j] < b.Min[j] {
+				b.Min[j] = flatCoords[i+j]
+			}
+			if flatCoords[i+j] > b.Max[j] {
+				b.Max[j] = flatCoords[i+j]
+			}
+		}
+	}
+	return b
+}
+
+// extendBounds extends b to include other and returns b.
+func (b *Bounds) extendBounds(other *Bounds) *Bounds {
+	for j := 0; j < len(b.Min) && j < len(other.Min); j++ {
+		if other.Min[j] < b.Min[j] {
+			b.Min[j] = other.Min[j]
+		}
+		if other.Max[j] > b.Max[j] {
+			b.Max[j] = other.Max[j]
+		}
+	}
+	return b
+}
+
+// An ErrWrongNumberOfCoords is returned when the wrong number of coordinates
+// is supplied for a layout.
+type ErrWrongNumberOfCoords struct {
+	Layout   Layout
+	Expected int
+	Actual   int
+}
+
+func (e ErrWrongNumberOfCoords) Error() string {
+	return "geom: wrong number of coordinates"
+}
+
+// deflate flattens coords (each with l.Stride() components) onto flatCoords
+// and returns the result.
+func deflate(flatCoords []float64, coords [][]float64, l Layout) ([]float64, error) {
+	stride := l.Stride()
+	for _, coord := range coords {
+		if len(coord) != stride {
+			return nil, ErrWrongNumberOfCoords{Layout: l, Expected: stride, Actual: len(coord)}
+		}
+		flatCoords = append(flatCoords, coord...)
+	}
+	return flatCoords, nil
+}
@@ -0,0 +1,50 @@
+package geom
+
+// A geom0 is the base for geometries composed of a single coordinate, i.e.
+// Point.
+type geom0 struct {
+	layout     Layout
+	stride     int
+	flatCoords []float64
+	srid       int
+}
+
+func newGeom0(layout Layout) geom0 {
+	return geom0{layout: layout, stride: layout.Stride()}
+}
+
+func (g *geom0) Bounds() *Bounds {
+	return NewBounds(g.Layout()).extendFlatCoords(g.flatCoords, 0, len(g.flatCoords), g.stride)
+}
+
+func (g *geom0) FlatCoords() []float64 {
+	return g.flatCoords
+}
+
+func (g *geom0) Layout() Layout {
+	return g.layout
+}
+
+func (g *geom0) Ends() []int {
+	return nil
+}
+
+func (g *geom0) Endss() [][]int {
+	return nil
+}
+
+func (g *geom0) SRID() int {
+	return g.srid
+}
+
+func (g *geom0) setSRID(srid int) {
+	g.srid = srid
+}
+
+func (g *geom0) Stride() int {
+	return g.stride
+}
+
+func (g *geom0) setFlatCoords(flatCoords []float64) {
+	g.flatCoords = flatCoords
+}
@@ -0,0 +1,11 @@
+package geom
+
+// A geom1 is the base for geometries composed of a single run of flat
+// coordinates, i.e. LineString and MultiPoint.
+type geom1 struct {
+	geom0
+}
+
+func newGeom1(layout Layout) geom1 {
+	return geom1{geom0: newGeom0(layout)}
+}
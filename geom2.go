@@ -0,0 +1,20 @@
+package geom
+
+// A geom2 is the base for geometries composed of several runs of flat
+// coordinates, i.e. Polygon, MultiLineString, and Triangle.
+type geom2 struct {
+	geom1
+	ends []int
+}
+
+func newGeom2(layout Layout) geom2 {
+	return geom2{geom1: newGeom1(layout)}
+}
+
+func (g *geom2) Ends() []int {
+	return g.ends
+}
+
+func (g *geom2) setEnds(ends []int) {
+	g.ends = ends
+}
@@ -0,0 +1,20 @@
+package geom
+
+// A geom3 is the base for geometries composed of collections of runs of flat
+// coordinates, i.e. MultiPolygon. TIN does not embed it.
+type geom3 struct {
+	geom2
+	endss [][]int
+}
+
+func newGeom3(layout Layout) geom3 {
+	return geom3{geom2: newGeom2(layout)}
+}
+
+func (g *geom3) Endss() [][]int {
+	return g.endss
+}
+
+func (g *geom3) setEndss(endss [][]int) {
+	g.endss = endss
+}
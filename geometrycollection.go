@@ -0,0 +1,96 @@
+package geom
+
+// A GeometryCollection represents a set of arbitrary geometries.
+type GeometryCollection struct {
+	geoms []T
+	srid  int
+}
+
+// NewGeometryCollection returns a new, empty GeometryCollection.
+func NewGeometryCollection() *GeometryCollection {
+	return &GeometryCollection{}
+}
+
+// SetSRID sets gc's SRID and returns gc.
+func (gc *GeometryCollection) SetSRID(srid int) *GeometryCollection {
+	gc.srid = srid
+	return gc
+}
+
+// Push appends g to gc and returns an error if g's layout is incompatible
+// with the layout of the geometries already in gc.
+func (gc *GeometryCollection) Push(g T) error {
+	if len(gc.geoms) > 0 && g.Layout() != gc.geoms[0].Layout() {
+		return ErrLayoutMismatch{Got: g.Layout(), Want: gc.geoms[0].Layout()}
+	}
+	gc.geoms = append(gc.geoms, g)
+	return nil
+}
+
+// Geoms returns gc's geometries.
+func (gc *GeometryCollection) Geoms() []T {
+	return gc.geoms
+}
+
+// NumGeoms returns the number of geometries in gc.
+func (gc *GeometryCollection) NumGeoms() int {
+	return len(gc.geoms)
+}
+
+// Layout returns the layout of gc's geometries, or NoLayout if gc is empty.
+func (gc *GeometryCollection) Layout() Layout {
+	if len(gc.geoms) == 0 {
+		return NoLayout
+	}
+	return gc.geoms[0].Layout()
+}
+
+// Stride returns the stride of gc's geometries.
+func (gc *GeometryCollection) Stride() int {
+	return gc.Layout().Stride()
+}
+
+// SRID returns gc's SRID.
+func (gc *GeometryCollection) SRID() int {
+	return gc.srid
+}
+
+// FlatCoords returns nil: a GeometryCollection has no flat coordinates of
+// its own. Use Bounds or walk Geoms to inspect its children's coordinates.
+func (gc *GeometryCollection) FlatCoords() []float64 {
+	return nil
+}
+
+// Ends returns nil.
+func (gc *GeometryCollection) Ends() []int {
+	return nil
+}
+
+// Endss returns nil.
+func (gc *GeometryCollection) Endss() [][]int {
+	return nil
+}
+
+// Bounds returns the bounding box containing all of gc's geometries. Each
+// child contributes its own Bounds rather than its FlatCoords, so a nested
+// GeometryCollection is unioned in recursively instead of contributing an
+// empty box.
+func (gc *GeometryCollection) Bounds() *Bounds {
+	b := NewBounds(gc.Layout())
+	for _, g := range gc.geoms {
+		b = b.extendBounds(g.Bounds())
+	}
+	return b
+}
+
+// An ErrLayoutMismatch is returned when a geometry is pushed onto a
+// GeometryCollection whose layout differs from the collection's existing
+// layout.
+type ErrLayoutMismatch struct {
+	Got  Layout
+	Want Layout
+}
+
+func (e ErrLayoutMismatch) Error() string {
+	return "geom: layout mismatch"
+}
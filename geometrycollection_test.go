@@ -0,0 +1,31 @@
+package geom
+
+import "testing"
+
+func TestGeometryCollectionBoundsRecursesIntoNestedCollections(t *testing.T) {
+	inner := NewGeometryCollection()
+	p, err := NewPoint(XY).SetCoords([]float64{5, 5})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	if err := inner.Push(p); err != nil {
+		t.Fatalf("Push == %v, want nil error", err)
+	}
+
+	outer := NewGeometryCollection()
+	p2, err := NewPoint(XY).SetCoords([]float64{0, 0})
+	if err != nil {
+		t.Fatalf("SetCoords == _, %v, want nil error", err)
+	}
+	if err := outer.Push(p2); err != nil {
+		t.Fatalf("Push == %v, want nil error", err)
+	}
+	if err := outer.Push(inner); err != nil {
+		t.Fatalf("Push == %v, want nil error", err)
+	}
+
+	b := outer.Bounds()
+	if !coordsEqual(b.Min, []float64{0, 0}) || !coordsEqual(b.Max, []float64{5, 5}) {
+		t.Errorf("Bounds() == %v, want Min [0 0] Max [5 5]", b)
+	}
+}
@@ -0,0 +1,43 @@
+package geom
+
+// A LineString represents a sequence of coordinates.
+type LineString struct {
+	geom1
+}
+
+// NewLineString returns a new, empty LineString with layout l.
+func NewLineString(l Layout) *LineString {
+	ls := new(LineString)
+	ls.geom1 = newGeom1(l)
+	return ls
+}
+
+// NewLineStringFlat returns a new LineString with layout l and flat
+// coordinates flatCoords.
+func NewLineStringFlat(l Layout, flatCoords []float64) *LineString {
+	ls := new(LineString)
+	ls.geom1 = newGeom1(l)
+	ls.setFlatCoords(flatCoords)
+	return ls
+}
+
+// SetSRID sets ls's SRID and returns ls.
+func (ls *LineString) SetSRID(srid int) *LineString {
+	ls.setSRID(srid)
+	return ls
+}
+
+// SetCoords sets the coordinates of ls and returns ls.
+func (ls *LineString) SetCoords(coords [][]float64) (*LineString, error) {
+	flatCoords, err := deflate(nil, coords, ls.layout)
+	if err != nil {
+		return nil, err
+	}
+	ls.setFlatCoords(flatCoords)
+	return ls, nil
+}
+
+// NumCoords returns the number of coordinates in ls.
+func (ls *LineString) NumCoords() int {
+	return len(ls.flatCoords) / ls.Stride()
+}
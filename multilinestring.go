@@ -0,0 +1,34 @@
+package geom
+
+// A MultiLineString represents a set of LineStrings.
+type MultiLineString struct {
+	geom2
+}
+
+// NewMultiLineString returns a new, empty MultiLineString with layout l.
+func NewMultiLineString(l Layout) *MultiLineString {
+	mls := new(MultiLineString)
+	mls.geom2 = newGeom2(l)
+	return mls
+}
+
+// NewMultiLineStringFlat returns a new MultiLineString with layout l, flat
+// coordinates flatCoords, and LineString end offsets ends.
+func NewMultiLineStringFlat(l Layout, flatCoords []float64, ends []int) *MultiLineString {
+	mls := new(MultiLineString)
+	mls.geom2 = newGeom2(l)
+	mls.setFlatCoords(flatCoords)
+	mls.setEnds(ends)
+	return mls
+}
+
+// SetSRID sets mls's SRID and returns mls.
+func (mls *MultiLineString) SetSRID(srid int) *MultiLineString {
+	mls.setSRID(srid)
+	return mls
+}
+
+// NumLineStrings returns the number of LineStrings in mls.
+func (mls *MultiLineString) NumLineStrings() int {
+	return len(mls.ends)
+}
@@ -0,0 +1,33 @@
+package geom
+
+// A MultiPoint represents a set of points.
+type MultiPoint struct {
+	geom1
+}
+
+// NewMultiPoint returns a new, empty MultiPoint with layout l.
+func NewMultiPoint(l Layout) *MultiPoint {
+	mp := new(MultiPoint)
+	mp.geom1 = newGeom1(l)
+	return mp
+}
+
+// NewMultiPointFlat returns a new MultiPoint with layout l and flat
+// coordinates flatCoords.
+func NewMultiPointFlat(l Layout, flatCoords []float64) *MultiPoint {
+	mp := new(MultiPoint)
+	mp.geom1 = newGeom1(l)
+	mp.setFlatCoords(flatCoords)
+	return mp
+}
+
+// SetSRID sets mp's SRID and returns mp.
+func (mp *MultiPoint) SetSRID(srid int) *MultiPoint {
+	mp.setSRID(srid)
+	return mp
+}
+
+// NumPoints returns the number of points in mp.
+func (mp *MultiPoint) NumPoints() int {
+	return len(mp.flatCoords) / mp.Stride()
+}
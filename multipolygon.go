@@ -0,0 +1,34 @@
+package geom
+
+// A MultiPolygon represents a set of Polygons.
+type MultiPolygon struct {
+	geom3
+}
+
+// NewMultiPolygon returns a new, empty MultiPolygon with layout l.
+func NewMultiPolygon(l Layout) *MultiPolygon {
+	mp := new(MultiPolygon)
+	mp.geom3 = newGeom3(l)
+	return mp
+}
+
+// NewMultiPolygonFlat returns a new MultiPolygon with layout l, flat
+// coordinates flatCoords, and per-Polygon ring end offsets endss.
+func NewMultiPolygonFlat(l Layout, flatCoords []float64, endss [][]int) *MultiPolygon {
+	mp := new(MultiPolygon)
+	mp.geom3 = newGeom3(l)
+	mp.setFlatCoords(flatCoords)
+	mp.setEndss(endss)
+	return mp
+}
+
+// SetSRID sets mp's SRID and returns mp.
+func (mp *MultiPolygon) SetSRID(srid int) *MultiPolygon {
+	mp.setSRID(srid)
+	return mp
+}
+
+// NumPolygons returns the number of Polygons in mp.
+func (mp *MultiPolygon) NumPolygons() int {
+	return len(mp.endss)
+}
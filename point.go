@@ -0,0 +1,42 @@
+package geom
+
+// A Point represents a single point with an arbitrary layout.
+type Point struct {
+	geom0
+}
+
+// NewPoint returns a new, empty Point with layout l.
+func NewPoint(l Layout) *Point {
+	p := new(Point)
+	p.geom0 = newGeom0(l)
+	return p
+}
+
+// NewPointFlat returns a new Point with layout l and flat coordinates
+// flatCoords.
+func NewPointFlat(l Layout, flatCoords []float64) *Point {
+	p := new(Point)
+	p.geom0 = newGeom0(l)
+	p.setFlatCoords(flatCoords)
+	return p
+}
+
+// SetSRID sets p's SRID and returns p.
+func (p *Point) SetSRID(srid int) *Point {
+	p.setSRID(srid)
+	return p
+}
+
+// SetCoords sets the coordinates of p and returns p.
+func (p *Point) SetCoords(coords []float64) (*Point, error) {
+	if len(coords) != p.stride {
+		return nil, ErrWrongNumberOfCoords{Layout: p.layout, Expected: p.stride, Actual: len(coords)}
+	}
+	p.setFlatCoords(append([]float64(nil), coords...))
+	return p, nil
+}
+
+// Coords returns p's coordinates.
+func (p *Point) Coords() []float64 {
+	return p.flatCoords
+}
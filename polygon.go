@@ -0,0 +1,54 @@
+package geom
+
+// A Polygon represents a polygon as a sequence of linear rings, the first of
+// which is the exterior ring.
+type Polygon struct {
+	geom2
+}
+
+// NewPolygon returns a new, empty Polygon with layout l.
+func NewPolygon(l Layout) *Polygon {
+	p := new(Polygon)
+	p.geom2 = newGeom2(l)
+	return p
+}
+
+// NewPolygonFlat returns a new Polygon with layout l, flat coordinates
+// flatCoords, and ring end offsets ends.
+func NewPolygonFlat(l Layout, flatCoords []float64, ends []int) *Polygon {
+	p := new(Polygon)
+	p.geom2 = newGeom2(l)
+	p.setFlatCoords(flatCoords)
+	p.setEnds(ends)
+	return p
+}
+
+// SetSRID sets p's SRID and returns p.
+func (p *Polygon) SetSRID(srid int) *Polygon {
+	p.setSRID(srid)
+	return p
+}
+
+// SetCoords sets the coordinates of p, a slice of linear rings. p's first
+// ring is its exterior ring. Any subsequent rings are interior rings
+// (holes). It returns p.
+func (p *Polygon) SetCoords(coords [][][]float64) (*Polygon, error) {
+	var flatCoords []float64
+	var ends []int
+	for _, ring := range coords {
+		var err error
+		flatCoords, err = deflate(flatCoords, ring, p.layout)
+		if err != nil {
+			return nil, err
+		}
+		ends = append(ends, len(flatCoords))
+	}
+	p.setFlatCoords(flatCoords)
+	p.setEnds(ends)
+	return p, nil
+}
+
+// NumLinearRings returns the number of linear rings in p.
+func (p *Polygon) NumLinearRings() int {
+	return len(p.ends)
+}
@@ -0,0 +1,102 @@
+package geom
+
+// A TIN is a triangulated irregular network: a collection of Triangles that
+// share a common vertex pool.
+type TIN struct {
+	layout    Layout
+	srid      int
+	Vertices  []float64
+	Triangles [][3]int32
+}
+
+// NewTIN returns a new, empty TIN with layout l.
+func NewTIN(l Layout) *TIN {
+	return &TIN{layout: l}
+}
+
+// SetSRID sets t's SRID and returns t.
+func (t *TIN) SetSRID(srid int) *TIN {
+	t.srid = srid
+	return t
+}
+
+// SetVertices sets t's vertex pool and returns t.
+func (t *TIN) SetVertices(vertices []float64) *TIN {
+	t.Vertices = vertices
+	return t
+}
+
+// SetTriangles sets t's triangle vertex indices and returns t.
+func (t *TIN) SetTriangles(triangles [][3]int32) *TIN {
+	t.Triangles = triangles
+	return t
+}
+
+// Layout returns t's layout.
+func (t *TIN) Layout() Layout {
+	return t.layout
+}
+
+// Stride returns t's stride.
+func (t *TIN) Stride() int {
+	return t.layout.Stride()
+}
+
+// SRID returns t's SRID.
+func (t *TIN) SRID() int {
+	return t.srid
+}
+
+// FlatCoords returns t's deduplicated vertex pool as flat coordinates. Unlike
+// every other level-2 geometry, this is NOT the Polygon-shaped sequence that
+// Ends describes: generic code that pairs FlatCoords with Ends the way it
+// would for a Polygon or MultiLineString will read garbage from a TIN. Use
+// NumTriangles and Triangle to consume a TIN's geometry generically instead.
+func (t *TIN) FlatCoords() []float64 {
+	return t.Vertices
+}
+
+// NumTriangles returns the number of triangles in t.
+func (t *TIN) NumTriangles() int {
+	return len(t.Triangles)
+}
+
+// Triangle returns the ith triangle of t as a standalone Triangle, expanding
+// its three vertex indices into the closed four-point ring used by the OGC
+// WKB encoding.
+func (t *TIN) Triangle(i int) *Triangle {
+	stride := t.Stride()
+	idx := t.Triangles[i]
+	flatCoords := make([]float64, 0, 4*stride)
+	for _, vi := range idx {
+		off := int(vi) * stride
+		flatCoords = append(flatCoords, t.Vertices[off:off+stride]...)
+	}
+	flatCoords = append(flatCoords, flatCoords[:stride]...)
+	return NewTriangle(t.layout).SetSRID(t.srid).SetFlatCoords(flatCoords)
+}
+
+// Ends returns the end offset of each triangle's ring within a flattened,
+// Polygon-shaped representation of t where every triangle is expanded to its
+// own closed four-point ring, as WKB/EWKB encode it. It does NOT index into
+// FlatCoords, which is t's deduplicated vertex pool, not that expanded
+// representation; Ends and FlatCoords must not be consumed together
+// generically. It exists so TIN satisfies the T interface, not for that use.
+func (t *TIN) Ends() []int {
+	stride := t.Stride()
+	ends := make([]int, len(t.Triangles))
+	for i := range t.Triangles {
+		ends[i] = (i + 1) * 4 * stride
+	}
+	return ends
+}
+
+// Endss returns nil, since a TIN's triangles each have a single ring.
+func (t *TIN) Endss() [][]int {
+	return nil
+}
+
+// Bounds returns the bounding box of t.
+func (t *TIN) Bounds() *Bounds {
+	return NewBounds(t.Layout()).extendFlatCoords(t.Vertices, 0, len(t.Vertices), t.Stride())
+}
@@ -0,0 +1,30 @@
+package geom
+
+import "testing"
+
+func TestTIN(t *testing.T) {
+	// Two triangles sharing an edge, hence two of their three vertices.
+	vertices := []float64{0, 0, 1, 0, 0, 1, 1, 1}
+	triangles := [][3]int32{{0, 1, 2}, {1, 3, 2}}
+	tin := NewTIN(XY).SetVertices(vertices).SetTriangles(triangles)
+
+	if got := tin.NumTriangles(); got != 2 {
+		t.Fatalf("NumTriangles() == %d, want 2", got)
+	}
+	if got := tin.Layout(); got != XY {
+		t.Errorf("Layout() == %v, want %v", got, XY)
+	}
+
+	tri := tin.Triangle(0)
+	want := [][]float64{{0, 0}, {1, 0}, {0, 1}, {0, 0}}
+	for i, w := range want {
+		if got := tri.Coord(i); !coordsEqual(got, w) {
+			t.Errorf("Triangle(0).Coord(%d) == %v, want %v", i, got, w)
+		}
+	}
+
+	b := tin.Bounds()
+	if !coordsEqual(b.Min, []float64{0, 0}) || !coordsEqual(b.Max, []float64{1, 1}) {
+		t.Errorf("Bounds() == %v, want Min [0 0] Max [1 1]", b)
+	}
+}
@@ -0,0 +1,67 @@
+package geom
+
+// A Triangle is a polygon with exactly one linear ring of four coordinates,
+// the first and last of which are equal.
+type Triangle struct {
+	geom2
+}
+
+// NewTriangle returns a new Triangle with layout l.
+func NewTriangle(l Layout) *Triangle {
+	t := new(Triangle)
+	t.geom2 = newGeom2(l)
+	return t
+}
+
+// An ErrTriangleNotClosed is returned by SetCoords when the first and last
+// of its four coordinates are not equal.
+type ErrTriangleNotClosed struct{}
+
+func (e ErrTriangleNotClosed) Error() string {
+	return "geom: triangle ring is not closed"
+}
+
+// SetSRID sets t's SRID and returns t.
+func (t *Triangle) SetSRID(srid int) *Triangle {
+	t.setSRID(srid)
+	return t
+}
+
+// SetCoords sets the coordinates of t. coords must contain exactly four
+// points, the first and last of which are equal.
+func (t *Triangle) SetCoords(coords [][]float64) (*Triangle, error) {
+	if len(coords) != 4 {
+		return nil, ErrWrongNumberOfCoords{Layout: t.layout, Expected: 4, Actual: len(coords)}
+	}
+	for i, c := range coords[0] {
+		if coords[3][i] != c {
+			return nil, ErrTriangleNotClosed{}
+		}
+	}
+	flatCoords, err := deflate(nil, coords, t.layout)
+	if err != nil {
+		return nil, err
+	}
+	t.setFlatCoords(flatCoords)
+	t.setEnds([]int{len(flatCoords)})
+	return t, nil
+}
+
+// SetFlatCoords sets t's flat coordinates and returns t.
+func (t *Triangle) SetFlatCoords(flatCoords []float64) *Triangle {
+	t.setFlatCoords(flatCoords)
+	t.setEnds([]int{len(flatCoords)})
+	return t
+}
+
+// NumCoords returns the number of coordinates in t. It is 4 after a
+// successful SetCoords; SetFlatCoords does not check the count.
+func (t *Triangle) NumCoords() int {
+	return len(t.flatCoords) / t.Stride()
+}
+
+// Coord returns the ith coordinate of t.
+func (t *Triangle) Coord(i int) []float64 {
+	stride := t.Stride()
+	return t.flatCoords[i*stride : (i+1)*stride]
+}
@@ -0,0 +1,48 @@
+package geom
+
+import "testing"
+
+func TestTriangleSetCoords(t *testing.T) {
+	closed := [][]float64{{0, 0}, {1, 0}, {0, 1}, {0, 0}}
+	if _, err := NewTriangle(XY).SetCoords(closed); err != nil {
+		t.Errorf("SetCoords(%v) == _, %v, want nil error", closed, err)
+	}
+
+	unclosed := [][]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	if _, err := NewTriangle(XY).SetCoords(unclosed); err != (ErrTriangleNotClosed{}) {
+		t.Errorf("SetCoords(%v) == _, %v, want ErrTriangleNotClosed", unclosed, err)
+	}
+
+	wrongLen := [][]float64{{0, 0}, {1, 0}, {0, 1}}
+	if _, err := NewTriangle(XY).SetCoords(wrongLen); err == nil {
+		t.Errorf("SetCoords(%v) == _, nil, want non-nil error", wrongLen)
+	}
+}
+
+func TestTriangleCoord(t *testing.T) {
+	coords := [][]float64{{0, 0}, {1, 0}, {0, 1}, {0, 0}}
+	tri, err := NewTriangle(XY).SetCoords(coords)
+	if err != nil {
+		t.Fatalf("SetCoords(%v) == _, %v, want nil error", coords, err)
+	}
+	if got := tri.NumCoords(); got != 4 {
+		t.Errorf("NumCoords() == %d, want 4", got)
+	}
+	for i, want := range coords {
+		if got := tri.Coord(i); !coordsEqual(got, want) {
+			t.Errorf("Coord(%d) == %v, want %v", i, got, want)
+		}
+	}
+}
+
+func coordsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}